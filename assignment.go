@@ -0,0 +1,187 @@
+package psrozklad
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GroupKind is the kind of the groups attending a lesson.
+type GroupKind int
+
+const (
+	// GroupSingle is one whole group.
+	GroupSingle GroupKind = iota
+	// GroupSubgroup is a subgroup of one group, e.g. "(підгр. 1)".
+	GroupSubgroup
+	// GroupCombined is a combined group made of several groups, e.g. "Збірна група 21Бд-СОмат, 22Бд-СОмат".
+	GroupCombined
+	// GroupStream is a stream of several groups, e.g. "Потік 21Бд-СОмат, 22Бд-СОмат".
+	GroupStream
+	// GroupUnknown is a list of groups with a prefix that is not known, kept in GroupAssignment.Prefix.
+	GroupUnknown
+)
+
+func (k GroupKind) String() string {
+	switch k {
+	case GroupSingle:
+		return "single"
+	case GroupSubgroup:
+		return "subgroup"
+	case GroupCombined:
+		return "combined"
+	case GroupStream:
+		return "stream"
+	default:
+		return "unknown"
+	}
+}
+
+// groupsType returns the GroupsType of a Lesson for the kind.
+func (k GroupKind) groupsType() string {
+	switch k {
+	case GroupSubgroup:
+		return "підгр"
+	case GroupCombined:
+		return "Збірна група"
+	case GroupStream:
+		return "Потік"
+	default:
+		return ""
+	}
+}
+
+// groupPrefixes are the known prefixes of group lists.
+var groupPrefixes = []struct {
+	prefix string
+	kind   GroupKind
+}{
+	{"Збірна група", GroupCombined},
+	{"Потік", GroupStream},
+}
+
+// GroupAssignment describes who attends a lesson.
+type GroupAssignment struct {
+	Kind GroupKind
+	// Names are the names of the member groups as the API wrote them.
+	Names []string
+	// Groups are the member groups resolved in the directory.
+	Groups []Group
+	// Subgroup is the number of the subgroup, 0 if the lesson is not for a numbered subgroup.
+	Subgroup int
+	// Label is the subgroup text, e.g. "(підгр. 1)", or the list of member groups.
+	Label string
+	// Prefix is the text before the list of groups for GroupUnknown.
+	Prefix string
+}
+
+// ParseGroupAssignment parses the group descriptor of a lesson. obj is the group the lesson was requested for,
+// it is used when the descriptor is empty or only names a subgroup.
+func ParseGroupAssignment(obj, descriptor string) GroupAssignment {
+	descriptor = strings.Join(strings.Fields(descriptor), " ")
+
+	// A subgroup is written in parentheses at the end, e.g. "22Бд-СОмат (підгр. 1)" or just "(підгр. 1)".
+	// Parentheses that are part of a group name, e.g. "11Бд-СОмат(ск)", are not a subgroup.
+	var label string
+	if strings.HasSuffix(descriptor, ")") {
+		if i := strings.LastIndex(descriptor, "("); i == 0 || i > 0 && descriptor[i-1] == ' ' {
+			label = descriptor[i:]
+			descriptor = strings.TrimSpace(descriptor[:i])
+		}
+	}
+	if label != "" && !strings.Contains(descriptor, ",") {
+		name := descriptor
+		if name == "" {
+			name = obj
+		}
+		return GroupAssignment{
+			Kind:     GroupSubgroup,
+			Names:    []string{name},
+			Subgroup: subgroupNumber(label),
+			Label:    label,
+		}
+	}
+
+	// Without a descriptor the lesson is for the requested group.
+	if descriptor == "" {
+		return GroupAssignment{Kind: GroupSingle, Names: []string{obj}}
+	}
+
+	assignment := parseGroupList(descriptor)
+	assignment.Subgroup = subgroupNumber(label)
+	return assignment
+}
+
+// parseGroupList parses a descriptor that is either a list of groups with a prefix or the name of one group.
+func parseGroupList(descriptor string) GroupAssignment {
+	// Look for a known prefix of a list of groups.
+	for _, p := range groupPrefixes {
+		if rest, ok := cutPrefixFold(descriptor, p.prefix); ok {
+			return GroupAssignment{Kind: p.kind, Names: splitGroupList(rest), Label: rest}
+		}
+	}
+
+	// A list with an unknown prefix: the prefix is everything before the first group name.
+	if strings.Contains(descriptor, ",") {
+		first := strings.SplitN(descriptor, ",", 2)[0]
+		if i := strings.LastIndex(first, " "); i != -1 {
+			rest := strings.TrimSpace(descriptor[i:])
+			return GroupAssignment{Kind: GroupUnknown, Names: splitGroupList(rest), Label: rest, Prefix: descriptor[:i]}
+		}
+		return GroupAssignment{Kind: GroupUnknown, Names: splitGroupList(descriptor), Label: descriptor}
+	}
+
+	// Otherwise the descriptor is the name of the group.
+	return GroupAssignment{Kind: GroupSingle, Names: []string{descriptor}}
+}
+
+// cutPrefixFold cuts prefix from s ignoring case and returns the rest without spaces around it.
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return "", false
+	}
+	rest := s[len(prefix):]
+	// The prefix must be a whole word.
+	if rest != "" && !strings.HasPrefix(rest, " ") && !strings.HasPrefix(rest, ":") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(rest, ":")), true
+}
+
+// splitGroupList splits a comma separated list of groups.
+func splitGroupList(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// subgroupNumber returns the first number in label, e.g. 1 for "(підгр. 1)", or 0 if there is none.
+func subgroupNumber(label string) int {
+	start := strings.IndexFunc(label, unicode.IsDigit)
+	if start == -1 {
+		return 0
+	}
+	end := start
+	for end < len(label) && label[end] >= '0' && label[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(label[start:end])
+	return n
+}
+
+// resolve looks up the member groups of the assignment in d and returns the references to them.
+func (g *GroupAssignment) resolve(d *Directory) []Ref {
+	g.Groups = nil
+	var refs []Ref
+	for _, name := range g.Names {
+		group, ok := d.Group(name)
+		g.Groups = append(g.Groups, group)
+		refs = append(refs, Ref{Raw: name, Resolved: ok})
+	}
+	return refs
+}
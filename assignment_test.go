@@ -0,0 +1,70 @@
+package psrozklad
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGroupAssignment(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		obj        string
+		descriptor string
+		want       GroupAssignment
+	}{
+		{
+			desc: "whole group",
+			obj:  "22Бд-СОмат",
+			want: GroupAssignment{Kind: GroupSingle, Names: []string{"22Бд-СОмат"}},
+		},
+		{
+			desc:       "named group",
+			descriptor: "22Бд-СОмат",
+			want:       GroupAssignment{Kind: GroupSingle, Names: []string{"22Бд-СОмат"}},
+		},
+		{
+			desc:       "subgroup of the requested group",
+			obj:        "22Бд-СОмат",
+			descriptor: "(підгр. 1)",
+			want:       GroupAssignment{Kind: GroupSubgroup, Names: []string{"22Бд-СОмат"}, Subgroup: 1, Label: "(підгр. 1)"},
+		},
+		{
+			desc:       "subgroup of a named group",
+			descriptor: "22Бд-СОмат  (підгр. 2)",
+			want:       GroupAssignment{Kind: GroupSubgroup, Names: []string{"22Бд-СОмат"}, Subgroup: 2, Label: "(підгр. 2)"},
+		},
+		{
+			desc:       "group name with parentheses",
+			descriptor: "11Бд-СОмат(ск)",
+			want:       GroupAssignment{Kind: GroupSingle, Names: []string{"11Бд-СОмат(ск)"}},
+		},
+		{
+			desc:       "combined group",
+			descriptor: "Збірна група 21Бд-СОмат, 22Бд-СОмат",
+			want:       GroupAssignment{Kind: GroupCombined, Names: []string{"21Бд-СОмат", "22Бд-СОмат"}, Label: "21Бд-СОмат, 22Бд-СОмат"},
+		},
+		{
+			desc:       "stream with subgroup",
+			descriptor: "потік: 21Бд-СОмат,22Бд-СОмат (підгр. 1)",
+			want:       GroupAssignment{Kind: GroupStream, Names: []string{"21Бд-СОмат", "22Бд-СОмат"}, Subgroup: 1, Label: "21Бд-СОмат,22Бд-СОмат"},
+		},
+		{
+			desc:       "unknown prefix",
+			descriptor: "Об'єднана група 21Бд-СОмат, 22Бд-СОмат",
+			want:       GroupAssignment{Kind: GroupUnknown, Names: []string{"21Бд-СОмат", "22Бд-СОмат"}, Label: "21Бд-СОмат, 22Бд-СОмат", Prefix: "Об'єднана група"},
+		},
+		{
+			desc:       "list without prefix",
+			descriptor: "21Бд-СОмат, 22Бд-СОмат",
+			want:       GroupAssignment{Kind: GroupUnknown, Names: []string{"21Бд-СОмат", "22Бд-СОмат"}, Label: "21Бд-СОмат, 22Бд-СОмат"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got := ParseGroupAssignment(tC.obj, tC.descriptor)
+			if !reflect.DeepEqual(got, tC.want) {
+				t.Errorf("want: %+v, got: %+v", tC.want, got)
+			}
+		})
+	}
+}
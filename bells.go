@@ -0,0 +1,221 @@
+package psrozklad
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Slot is a lesson slot of the bell schedule, e.g. the first pair from 09:00 to 10:20.
+type Slot struct {
+	Number int
+	// Start and End are the times of day as durations since midnight.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Duration returns the length of the slot.
+func (s Slot) Duration() time.Duration {
+	return s.End - s.Start
+}
+
+// On returns the start and the end of the slot on the day of t, in the location of t.
+func (s Slot) On(t time.Time) (start, end time.Time) {
+	return atClock(t, s.Start), atClock(t, s.End)
+}
+
+func (s Slot) String() string {
+	return fmt.Sprintf("%d: %s-%s", s.Number, formatClock(s.Start), formatClock(s.End))
+}
+
+// Break is the time between two consecutive slots.
+type Break struct {
+	// After is the number of the slot the break follows.
+	After int
+	Start time.Duration
+	End   time.Duration
+}
+
+// Duration returns the length of the break.
+func (b Break) Duration() time.Duration {
+	return b.End - b.Start
+}
+
+// BellSchedule holds the times of the lesson slots of the university.
+type BellSchedule struct {
+	// Slots are sorted by their start.
+	Slots []Slot
+	// Location is the timezone of the slot times, the location of the given time if nil.
+	Location *time.Location
+}
+
+// NewBellSchedule creates a bell schedule from slots, which must not overlap.
+func NewBellSchedule(loc *time.Location, slots ...Slot) (*BellSchedule, error) {
+	b := &BellSchedule{Slots: append([]Slot(nil), slots...), Location: loc}
+	sort.Slice(b.Slots, func(i, j int) bool { return b.Slots[i].Start < b.Slots[j].Start })
+	numbers := make(map[int]bool, len(b.Slots))
+	for i, slot := range b.Slots {
+		if slot.Start < 0 || slot.End > 24*time.Hour || slot.End <= slot.Start {
+			return nil, fmt.Errorf("invalid times of slot %v", slot)
+		}
+		if i > 0 && slot.Start < b.Slots[i-1].End {
+			return nil, fmt.Errorf("slot %v overlaps slot %v", slot, b.Slots[i-1])
+		}
+		if numbers[slot.Number] {
+			return nil, fmt.Errorf("slot %d is given twice", slot.Number)
+		}
+		numbers[slot.Number] = true
+	}
+	return b, nil
+}
+
+// LearnBellSchedule learns the bell schedule from lessons.
+// The times of every slot are the ones most of its lessons have, lessons without a number are ignored.
+// It returns an error like NewBellSchedule if the learned slots overlap, e.g. when the lessons of a slot disagree.
+func LearnBellSchedule(lessons []Lesson) (*BellSchedule, error) {
+	b := &BellSchedule{}
+
+	// Count the times of every slot.
+	counts := make(map[int]map[Slot]int)
+	for _, lesson := range lessons {
+		if lesson.Number == 0 || lesson.StartTime.IsZero() || !lesson.EndTime.After(lesson.StartTime) {
+			continue
+		}
+		if b.Location == nil {
+			b.Location = lesson.StartTime.Location()
+		}
+		start := lesson.StartTime.In(b.Location)
+		slot := Slot{Number: lesson.Number, Start: clockOf(start), End: clockOf(start) + lesson.EndTime.Sub(lesson.StartTime)}
+		if counts[slot.Number] == nil {
+			counts[slot.Number] = make(map[Slot]int)
+		}
+		counts[slot.Number][slot]++
+	}
+
+	// Take the most common times, the earliest one on a tie.
+	for _, times := range counts {
+		var best Slot
+		for slot, n := range times {
+			if n > times[best] || n == times[best] && slot.Start < best.Start {
+				best = slot
+			}
+		}
+		b.Slots = append(b.Slots, best)
+	}
+	bells, err := NewBellSchedule(b.Location, b.Slots...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to learn bell schedule: %w", err)
+	}
+	return bells, nil
+}
+
+// WithBellSchedule sets the bell schedule of the university instead of learning it from the lessons.
+// The schedule is validated like by NewBellSchedule.
+func WithBellSchedule(b *BellSchedule) Option {
+	return func(o *options) error {
+		if b == nil || len(b.Slots) == 0 {
+			return errors.New("bell schedule is empty")
+		}
+		bells, err := NewBellSchedule(b.Location, b.Slots...)
+		if err != nil {
+			return fmt.Errorf("invalid bell schedule: %w", err)
+		}
+		o.bells = bells
+		return nil
+	}
+}
+
+// bellSchedule returns the bell schedule of the Api, or ErrNoBellSchedule if it has none.
+// It is not learned from the lessons of the search, a slot nobody has a lesson in would be missing.
+func (a *Api) bellSchedule() (*BellSchedule, error) {
+	if a.Bells == nil || len(a.Bells.Slots) == 0 {
+		return nil, ErrNoBellSchedule
+	}
+	return a.Bells, nil
+}
+
+// Slot returns the slot with the number n.
+func (b *BellSchedule) Slot(n int) (Slot, bool) {
+	for _, slot := range b.Slots {
+		if slot.Number == n {
+			return slot, true
+		}
+	}
+	return Slot{}, false
+}
+
+// Breaks returns the breaks between the consecutive slots.
+func (b *BellSchedule) Breaks() []Break {
+	var breaks []Break
+	for i := 1; i < len(b.Slots); i++ {
+		if b.Slots[i].Start > b.Slots[i-1].End {
+			breaks = append(breaks, Break{After: b.Slots[i-1].Number, Start: b.Slots[i-1].End, End: b.Slots[i].Start})
+		}
+	}
+	return breaks
+}
+
+// SlotAt returns the slot that is going on at t.
+func (b *BellSchedule) SlotAt(t time.Time) (Slot, bool) {
+	c := clockOf(b.in(t))
+	for _, slot := range b.Slots {
+		if slot.Start <= c && c < slot.End {
+			return slot, true
+		}
+	}
+	return Slot{}, false
+}
+
+// NextSlot returns the first slot that starts after t and the time it starts, which can be on a later day.
+func (b *BellSchedule) NextSlot(t time.Time) (Slot, time.Time, bool) {
+	if len(b.Slots) == 0 {
+		return Slot{}, time.Time{}, false
+	}
+	t = b.in(t)
+	c := clockOf(t)
+	for _, slot := range b.Slots {
+		if slot.Start > c {
+			return slot, atClock(t, slot.Start), true
+		}
+	}
+	// The first slot of the next day.
+	slot := b.Slots[0]
+	return slot, atClock(t.AddDate(0, 0, 1), slot.Start), true
+}
+
+// SlotsOn returns the start and the end of every slot on the day of t.
+func (b *BellSchedule) SlotsOn(t time.Time) [][2]time.Time {
+	t = b.in(t)
+	times := make([][2]time.Time, 0, len(b.Slots))
+	for _, slot := range b.Slots {
+		start, end := slot.On(t)
+		times = append(times, [2]time.Time{start, end})
+	}
+	return times
+}
+
+// in returns t in the location of the schedule.
+func (b *BellSchedule) in(t time.Time) time.Time {
+	if b.Location == nil {
+		return t
+	}
+	return t.In(b.Location)
+}
+
+// clockOf returns the time of day of t as a duration since midnight.
+func clockOf(t time.Time) time.Duration {
+	h, m, s := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+}
+
+// atClock returns the time c after midnight on the day of t, by the wall clock so that DST changes don't shift it.
+func atClock(t time.Time, c time.Duration) time.Time {
+	y, mo, d := t.Date()
+	return time.Date(y, mo, d, int(c/time.Hour), int(c%time.Hour/time.Minute), int(c%time.Minute/time.Second), 0, t.Location())
+}
+
+// formatClock formats a time of day like "09:00".
+func formatClock(c time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(c/time.Hour), int(c%time.Hour/time.Minute))
+}
@@ -0,0 +1,124 @@
+package psrozklad
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// clock returns the time of day h:m as a duration since midnight.
+func clock(h, m int) time.Duration {
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+}
+
+func TestLearnBellSchedule(t *testing.T) {
+	lesson := func(n, h, m int) Lesson {
+		start := time.Date(2023, time.October, 16, h, m, 0, 0, Kyiv)
+		return Lesson{Number: n, StartTime: start, EndTime: start.Add(80 * time.Minute)}
+	}
+	lessons := []Lesson{
+		lesson(1, 9, 0), lesson(1, 9, 0), lesson(1, 8, 30),
+		lesson(2, 10, 30),
+		lesson(3, 12, 20),
+		{Title: "without a number"},
+	}
+
+	b, err := LearnBellSchedule(lessons)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Slot{
+		{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		{Number: 3, Start: clock(12, 20), End: clock(13, 40)},
+	}
+	if !reflect.DeepEqual(b.Slots, want) || b.Location != Kyiv {
+		t.Errorf("unexpected schedule: %v, %v", b.Slots, b.Location)
+	}
+	wantBreaks := []Break{
+		{After: 1, Start: clock(10, 20), End: clock(10, 30)},
+		{After: 2, Start: clock(11, 50), End: clock(12, 20)},
+	}
+	if got := b.Breaks(); !reflect.DeepEqual(got, wantBreaks) {
+		t.Errorf("unexpected breaks: %v", got)
+	}
+
+	// A make-up lesson numbered 2 but held at the time of the first pair overlaps it.
+	if _, err := LearnBellSchedule([]Lesson{lesson(1, 9, 0), lesson(2, 9, 30)}); err == nil {
+		t.Error("expected an error for overlapping slots")
+	}
+}
+
+func TestNewBellSchedule(t *testing.T) {
+	_, err := NewBellSchedule(Kyiv, Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)}, Slot{Number: 2, Start: clock(10, 0), End: clock(11, 20)})
+	if err == nil {
+		t.Error("expected an error for overlapping slots")
+	}
+	_, err = NewBellSchedule(Kyiv, Slot{Number: 1, Start: clock(10, 0), End: clock(9, 0)})
+	if err == nil {
+		t.Error("expected an error for a slot that ends before it starts")
+	}
+	_, err = NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		Slot{Number: 1, Start: clock(12, 20), End: clock(13, 40)},
+	)
+	if err == nil {
+		t.Error("expected an error for a number given twice")
+	}
+
+	// The option validates a literal schedule too, and sorts its slots.
+	_, err = New("https://dekanat.zu.edu.ua/", WithBellSchedule(&BellSchedule{Slots: []Slot{
+		{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		{Number: 2, Start: clock(10, 0), End: clock(11, 20)},
+	}}))
+	if err == nil {
+		t.Error("expected an error for overlapping slots")
+	}
+	api, err := New("https://dekanat.zu.edu.ua/", WithBellSchedule(&BellSchedule{Slots: []Slot{
+		{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+	}}))
+	if err != nil || api.Bells.Slots[0].Number != 1 {
+		t.Errorf("unexpected result: %v, %v", api, err)
+	}
+}
+
+func TestSlotAt(t *testing.T) {
+	b, err := NewBellSchedule(Kyiv,
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	day := func(h, m int) time.Time {
+		return time.Date(2023, time.October, 16, h, m, 0, 0, Kyiv)
+	}
+
+	testCases := []struct {
+		desc     string
+		at       time.Time
+		slot     int
+		next     int
+		nextTime time.Time
+	}{
+		{"before the first slot", day(8, 0), 0, 1, day(9, 0)},
+		{"in the first slot", day(9, 30), 1, 2, day(10, 30)},
+		{"in a break", day(10, 25), 0, 2, day(10, 30)},
+		{"after the last slot", day(12, 0), 0, 1, day(9, 0).AddDate(0, 0, 1)},
+		{"in another timezone", day(9, 30).UTC(), 1, 2, day(10, 30)},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			slot, _ := b.SlotAt(tC.at)
+			if slot.Number != tC.slot {
+				t.Errorf("SlotAt: want %d, got %d", tC.slot, slot.Number)
+			}
+			next, start, ok := b.NextSlot(tC.at)
+			if !ok || next.Number != tC.next || !start.Equal(tC.nextTime) {
+				t.Errorf("NextSlot: want %d at %v, got %d at %v", tC.next, tC.nextTime, next.Number, start)
+			}
+		})
+	}
+}
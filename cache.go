@@ -0,0 +1,182 @@
+package psrozklad
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheConfig configures a Cache.
+type CacheConfig struct {
+	// ListTTL is how long lists of groups, rooms and teachers are kept.
+	ListTTL time.Duration
+	// LessonTTL is how long lessons are kept.
+	LessonTTL time.Duration
+	// MaxEntries is the maximum number of entries, the least recently used are evicted first.
+	// Zero means no limit.
+	MaxEntries int
+}
+
+// DefaultCacheConfig keeps object lists for a day and lessons for five minutes.
+var DefaultCacheConfig = CacheConfig{
+	ListTTL:    24 * time.Hour,
+	LessonTTL:  5 * time.Minute,
+	MaxEntries: 1000,
+}
+
+// CacheStats holds the statistics of a Cache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// Cache is an in-memory LRU cache with expiration for the responses of the Api.
+// It is safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	cfg   CacheConfig
+	ll    *list.List
+	items map[cacheKey]*list.Element
+	stats CacheStats
+}
+
+// cacheKey identifies a cached response.
+type cacheKey struct {
+	// kind is "list" for object lists and "lessons" for lessons.
+	kind string
+	// obj is the type of the object: "group", "room" or "teacher".
+	obj        string
+	id         int
+	begin, end string
+}
+
+// cacheEntry is a value stored in the cache.
+type cacheEntry struct {
+	key     cacheKey
+	value   interface{}
+	expires time.Time
+}
+
+// NewCache creates a new Cache.
+func NewCache(cfg CacheConfig) *Cache {
+	return &Cache{
+		cfg:   cfg,
+		ll:    list.New(),
+		items: make(map[cacheKey]*list.Element),
+	}
+}
+
+// listKey returns the key of the list of objects of type obj.
+func listKey(obj string) cacheKey {
+	return cacheKey{kind: "list", obj: obj}
+}
+
+// lessonsKey returns the key of the lessons of obj between begin and end.
+func lessonsKey(obj Object, begin, end time.Time) cacheKey {
+	return cacheKey{kind: "lessons", obj: obj.type_obj(), id: obj.ID(), begin: formatDate(begin), end: formatDate(end)}
+}
+
+// ttl returns the time to live of the entries of the given kind.
+func (c *Cache) ttl(key cacheKey) time.Duration {
+	if key.kind == "list" {
+		return c.cfg.ListTTL
+	}
+	return c.cfg.LessonTTL
+}
+
+// get returns the value stored under key if it is not expired.
+func (c *Cache) get(key cacheKey) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		// Expired entries are removed on access.
+		c.ll.Remove(el)
+		delete(c.items, key)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+// set stores the value under key, evicting the least recently used entries if needed.
+func (c *Cache) set(key cacheKey, value interface{}) {
+	if c == nil {
+		return
+	}
+	ttl := c.ttl(key)
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, value: value, expires: expires})
+	for c.cfg.MaxEntries > 0 && c.ll.Len() > c.cfg.MaxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// remove removes the entry stored under key.
+func (c *Cache) remove(key cacheKey) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// Purge removes all entries from the cache.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[cacheKey]*list.Element)
+}
+
+// Stats returns the statistics of the cache.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.ll.Len()
+	return stats
+}
+
+// CacheStats returns the statistics of the Api cache, or zero stats if the Api has no cache.
+func (a *Api) CacheStats() CacheStats {
+	if a.Cache == nil {
+		return CacheStats{}
+	}
+	return a.Cache.Stats()
+}
@@ -0,0 +1,107 @@
+package psrozklad
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// countingHttpClient returns the same body for every request and counts the calls.
+type countingHttpClient struct {
+	body  string
+	calls int
+}
+
+func (c *countingHttpClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(c.body)),
+	}, nil
+}
+
+func TestCacheApi(t *testing.T) {
+	client := &countingHttpClient{body: `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "teacher": "Горобець С.М."}], "code": "0"}}`}
+	api := newTestApi(t)
+	api.HttpClient = client
+	api.Cache = NewCache(DefaultCacheConfig)
+	api.Directory.SetTeachers([]Teacher{{ShortName: "Горобець С.М.", Id: 420}})
+
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, time.UTC)
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	for i := 0; i < 3; i++ {
+		lessons, err := api.GetLessons(group, day, day)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(lessons) != 1 {
+			t.Fatalf("want 1 lesson, got %d", len(lessons))
+		}
+		// Changing the result, also its slices, must not change the cache.
+		lessons[0].Title = "changed"
+		lessons[0].GroupRefs[0].Raw = "changed"
+		lessons[0].Assignment.Names[0] = "changed"
+		lessons[0].Teachers[0].ShortName = "changed"
+		lessons[0].Raw[0] = 'x'
+	}
+	if client.calls != 1 {
+		t.Errorf("want 1 request, got %d", client.calls)
+	}
+	lessons, _ := api.GetLessons(group, day, day)
+	if lessons[0].Title != "" || lessons[0].GroupRefs[0].Raw != "21Бд-СОмат" || lessons[0].Assignment.Names[0] != "21Бд-СОмат" ||
+		lessons[0].Teachers[0].ShortName != "Горобець С.М." || lessons[0].Raw[0] != '{' {
+		t.Errorf("cached lessons were modified: %+v", lessons[0])
+	}
+
+	// Another date range is another entry.
+	if _, err := api.GetLessons(group, day, day.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.calls != 2 {
+		t.Errorf("want 2 requests, got %d", client.calls)
+	}
+
+	want := CacheStats{Hits: 3, Misses: 2, Entries: 2}
+	if got := api.CacheStats(); got != want {
+		t.Errorf("want: %+v, got: %+v", want, got)
+	}
+}
+
+func TestCacheExpiration(t *testing.T) {
+	c := NewCache(CacheConfig{ListTTL: time.Hour, LessonTTL: time.Millisecond})
+	lessons := lessonsKey(Group{Id: 1}, time.Time{}, time.Time{})
+	c.set(lessons, []Lesson{{}})
+	c.set(listKey("group"), []Group{{}})
+
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.get(lessons); ok {
+		t.Errorf("lessons should have expired")
+	}
+	if _, ok := c.get(listKey("group")); !ok {
+		t.Errorf("group list should not have expired")
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	c := NewCache(CacheConfig{ListTTL: time.Hour, LessonTTL: time.Hour, MaxEntries: 2})
+	c.set(listKey("group"), []Group{})
+	c.set(listKey("room"), []Room{})
+	// Use the groups so that the rooms are the least recently used.
+	c.get(listKey("group"))
+	c.set(listKey("teacher"), []Teacher{})
+
+	if _, ok := c.get(listKey("room")); ok {
+		t.Errorf("rooms should have been evicted")
+	}
+	if _, ok := c.get(listKey("group")); !ok {
+		t.Errorf("groups should be cached")
+	}
+	if _, ok := c.get(listKey("teacher")); !ok {
+		t.Errorf("teachers should be cached")
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
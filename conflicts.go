@@ -0,0 +1,183 @@
+package psrozklad
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConflictKind is the kind of the object booked twice.
+type ConflictKind int
+
+const (
+	// ConflictTeacher is a teacher who has two lessons at once.
+	ConflictTeacher ConflictKind = iota
+	// ConflictRoom is a room with two lessons at once.
+	ConflictRoom
+	// ConflictGroup is a group, or the same subgroup of it, with two lessons at once.
+	ConflictGroup
+)
+
+func (k ConflictKind) String() string {
+	switch k {
+	case ConflictTeacher:
+		return "teacher"
+	case ConflictRoom:
+		return "room"
+	default:
+		return "group"
+	}
+}
+
+// Conflict is a teacher, a room or a group booked for two lessons that overlap.
+type Conflict struct {
+	Kind ConflictKind
+	// Name is the name of the teacher, the room or the group.
+	Name string
+	// A and B are the overlapping lessons, A starts first.
+	A, B Lesson
+}
+
+// event is a lesson as it takes place: the lessons of a stream or a combined group
+// and the same lesson seen from the group, the teacher and the room queries are one event.
+type event struct {
+	lesson Lesson
+	// resources maps the keys of the teachers, rooms and groups to their names.
+	resources map[resourceKey]string
+	// subgroups maps the keys of the groups to the subgroup attending, 0 for the whole group.
+	subgroups map[resourceKey]int
+}
+
+// resourceKey identifies a teacher, a room or a group, by ID if it is resolved and by the text of the API otherwise.
+type resourceKey struct {
+	kind ConflictKind
+	id   string
+}
+
+// newResourceKey returns the key of a resolved object or of the raw text.
+func newResourceKey(kind ConflictKind, id int, raw string) resourceKey {
+	if id != 0 {
+		return resourceKey{kind, strconv.Itoa(id)}
+	}
+	return resourceKey{kind, "raw:" + strings.ToLower(strings.Join(strings.Fields(raw), " "))}
+}
+
+// resources returns the teachers, the room and the groups of the lesson.
+func (l Lesson) resources() map[resourceKey]string {
+	res := make(map[resourceKey]string)
+	for _, t := range l.Teachers {
+		if t.Id != 0 {
+			res[newResourceKey(ConflictTeacher, t.Id, "")] = t.ShortName
+		}
+	}
+	for _, ref := range append([]Ref{l.TeacherRef}, l.AdditionalTeacherRefs...) {
+		if ref.missing() {
+			res[newResourceKey(ConflictTeacher, 0, ref.Raw)] = ref.Raw
+		}
+	}
+	if l.Room.Id != 0 {
+		res[newResourceKey(ConflictRoom, l.Room.Id, "")] = l.Room.FullName
+	} else if l.RoomRef.Raw != "" {
+		res[newResourceKey(ConflictRoom, 0, l.RoomRef.Raw)] = l.RoomRef.Raw
+	}
+	for _, g := range l.Groups {
+		if g.Id != 0 {
+			res[newResourceKey(ConflictGroup, g.Id, "")] = g.Name
+		}
+	}
+	for _, ref := range l.GroupRefs {
+		if ref.missing() {
+			res[newResourceKey(ConflictGroup, 0, ref.Raw)] = ref.Raw
+		}
+	}
+	return res
+}
+
+// sameEvent reports whether the lesson is a part of the event: the same time, title and room,
+// and a common teacher if both name one.
+func (e *event) sameEvent(l Lesson) bool {
+	if !e.lesson.StartTime.Equal(l.StartTime) || !e.lesson.EndTime.Equal(l.EndTime) || !sameName(e.lesson.Title, l.Title) {
+		return false
+	}
+	res := l.resources()
+	var eRoom, lRoom, eTeacher, lTeacher, commonRoom, commonTeacher bool
+	for k := range e.resources {
+		eRoom = eRoom || k.kind == ConflictRoom
+		eTeacher = eTeacher || k.kind == ConflictTeacher
+	}
+	for k := range res {
+		lRoom = lRoom || k.kind == ConflictRoom
+		lTeacher = lTeacher || k.kind == ConflictTeacher
+		if _, ok := e.resources[k]; ok {
+			commonRoom = commonRoom || k.kind == ConflictRoom
+			commonTeacher = commonTeacher || k.kind == ConflictTeacher
+		}
+	}
+	return (commonRoom || !eRoom || !lRoom) && (commonTeacher || !eTeacher || !lTeacher)
+}
+
+// add adds the resources of the lesson to the event.
+func (e *event) add(l Lesson) {
+	for k, name := range l.resources() {
+		e.resources[k] = name
+		if k.kind == ConflictGroup {
+			e.subgroups[k] = l.SubgroupNumber
+		}
+	}
+}
+
+// FindConflicts returns the teachers, rooms and groups booked for overlapping lessons.
+// The lessons can come from any mix of group, teacher and room queries: the same lesson seen twice,
+// the lessons of a stream or a combined group and the lessons of different subgroups of a group are not conflicts.
+func FindConflicts(lessons []Lesson) []Conflict {
+	// Join the lessons into events.
+	var events []*event
+	for _, lesson := range lessons {
+		var found *event
+		for _, e := range events {
+			if e.sameEvent(lesson) {
+				found = e
+				break
+			}
+		}
+		if found == nil {
+			found = &event{lesson: lesson, resources: make(map[resourceKey]string), subgroups: make(map[resourceKey]int)}
+			events = append(events, found)
+		}
+		found.add(lesson)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].lesson.StartTime.Before(events[j].lesson.StartTime) })
+
+	// Find the objects of the overlapping events.
+	var conflicts []Conflict
+	for i, a := range events {
+		for _, b := range events[i+1:] {
+			if !b.lesson.StartTime.Before(a.lesson.EndTime) {
+				// The events are sorted by start, no later event overlaps a.
+				break
+			}
+			if !overlaps(a.lesson.StartTime, a.lesson.EndTime, b.lesson.StartTime, b.lesson.EndTime) {
+				continue
+			}
+			var found []Conflict
+			for k, name := range a.resources {
+				if _, ok := b.resources[k]; !ok {
+					continue
+				}
+				// Different subgroups of a group can have lessons at once.
+				if k.kind == ConflictGroup && a.subgroups[k] != 0 && b.subgroups[k] != 0 && a.subgroups[k] != b.subgroups[k] {
+					continue
+				}
+				found = append(found, Conflict{Kind: k.kind, Name: name, A: a.lesson, B: b.lesson})
+			}
+			sort.Slice(found, func(i, j int) bool {
+				if found[i].Kind != found[j].Kind {
+					return found[i].Kind < found[j].Kind
+				}
+				return found[i].Name < found[j].Name
+			})
+			conflicts = append(conflicts, found...)
+		}
+	}
+	return conflicts
+}
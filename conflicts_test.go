@@ -0,0 +1,111 @@
+package psrozklad
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindConflicts(t *testing.T) {
+	at := func(h, m int) time.Time {
+		return time.Date(2023, time.October, 16, h, m, 0, 0, Kyiv)
+	}
+	gorobets := Teacher{ShortName: "Горобець С.М.", Id: 420}
+	yatsenko := Teacher{ShortName: "Яценко О.С.", Id: 486}
+	room320 := Room{Name: "320", Block: "№1", FullName: "320/№1", Id: 36}
+	room101 := Room{Name: "101", Block: "№1", FullName: "101/№1", Id: 37}
+	g21 := Group{Name: "21Бд-СОмат", Id: 11}
+	g22 := Group{Name: "22Бд-СОмат", Id: 12}
+	lesson := func(title string, h, m int, teacher Teacher, room Room, subgroup int, groups ...Group) Lesson {
+		return Lesson{
+			Title:          title,
+			Teacher:        teacher,
+			Teachers:       []Teacher{teacher},
+			Room:           room,
+			Groups:         groups,
+			SubgroupNumber: subgroup,
+			StartTime:      at(h, m),
+			EndTime:        at(h, m).Add(80 * time.Minute),
+		}
+	}
+
+	testCases := []struct {
+		desc    string
+		lessons []Lesson
+		want    []ConflictKind
+	}{
+		{
+			desc: "stream seen from both groups and the teacher",
+			lessons: []Lesson{
+				lesson("Графіка", 9, 0, gorobets, room320, 0, g21, g22),
+				lesson("Графіка", 9, 0, gorobets, room320, 0, g21),
+				lesson("Графіка", 9, 0, gorobets, room320, 0, g22),
+			},
+		},
+		{
+			desc: "different subgroups at once",
+			lessons: []Lesson{
+				lesson("Мережі", 9, 0, gorobets, room320, 1, g21),
+				lesson("Мережі", 9, 0, yatsenko, room101, 2, g21),
+			},
+		},
+		{
+			desc: "same subgroup twice",
+			lessons: []Lesson{
+				lesson("Мережі", 9, 0, gorobets, room320, 1, g21),
+				lesson("Графіка", 9, 30, yatsenko, room101, 1, g21),
+			},
+			want: []ConflictKind{ConflictGroup},
+		},
+		{
+			desc: "double-booked teacher and room",
+			lessons: []Lesson{
+				lesson("Мережі", 9, 0, gorobets, room320, 0, g21),
+				lesson("Графіка", 9, 0, gorobets, room320, 0, g22),
+			},
+			want: []ConflictKind{ConflictTeacher, ConflictRoom},
+		},
+		{
+			desc: "following lessons",
+			lessons: []Lesson{
+				lesson("Мережі", 9, 0, gorobets, room320, 0, g21),
+				lesson("Графіка", 10, 20, gorobets, room320, 0, g21),
+			},
+		},
+		{
+			desc: "unresolved teacher",
+			lessons: []Lesson{
+				{Title: "Мережі", TeacherRef: Ref{Raw: "Новенький Н.Н."}, StartTime: at(9, 0), EndTime: at(10, 20)},
+				{Title: "Графіка", TeacherRef: Ref{Raw: "новенький  Н.Н."}, StartTime: at(10, 0), EndTime: at(11, 20)},
+			},
+			want: []ConflictKind{ConflictTeacher},
+		},
+		{
+			desc: "unresolved groups",
+			lessons: []Lesson{
+				{Title: "Мережі", Groups: []Group{{}}, GroupRefs: []Ref{{Raw: "AAA"}}, StartTime: at(9, 0), EndTime: at(10, 20)},
+				{Title: "Графіка", Groups: []Group{{}}, GroupRefs: []Ref{{Raw: "BBB"}}, StartTime: at(9, 0), EndTime: at(10, 20)},
+			},
+		},
+		{
+			desc: "same unresolved group",
+			lessons: []Lesson{
+				{Title: "Мережі", Groups: []Group{{}}, GroupRefs: []Ref{{Raw: "AAA"}}, StartTime: at(9, 0), EndTime: at(10, 20)},
+				{Title: "Графіка", Groups: []Group{{}}, GroupRefs: []Ref{{Raw: "aaa"}}, StartTime: at(9, 0), EndTime: at(10, 20)},
+			},
+			want: []ConflictKind{ConflictGroup},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got := FindConflicts(tC.lessons)
+			if len(got) != len(tC.want) {
+				t.Fatalf("want %v, got %+v", tC.want, got)
+			}
+			for i, c := range got {
+				if c.Kind != tC.want[i] || c.A.StartTime.After(c.B.StartTime) {
+					t.Errorf("conflict %d: want %v, got %+v", i, tC.want[i], c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package psrozklad
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChangeKind is the kind of a change of the timetable.
+type ChangeKind int
+
+const (
+	// ChangeAdded is a new lesson.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved is a lesson that is not in the timetable anymore.
+	ChangeRemoved
+	// ChangeMoved is a lesson with a new time or room.
+	ChangeMoved
+	// ChangeTeacherChanged is a lesson with a new teacher.
+	ChangeTeacherChanged
+	// ChangeReplacementAdded is a lesson that got a replacement notice.
+	ChangeReplacementAdded
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeMoved:
+		return "moved"
+	case ChangeTeacherChanged:
+		return "teacher-changed"
+	default:
+		return "replacement-added"
+	}
+}
+
+// Change is a change of a lesson between two snapshots of the timetable.
+type Change struct {
+	Kind ChangeKind
+	// Old is the lesson in the old snapshot, zero for ChangeAdded.
+	Old Lesson
+	// New is the lesson in the new snapshot, zero for ChangeRemoved.
+	New Lesson
+}
+
+// identity returns what stays the same when a lesson is moved or gets another teacher:
+// the title, the type, the groups and the subgroup.
+func (l Lesson) identity() string {
+	var groups []string
+	for _, ref := range l.GroupRefs {
+		groups = append(groups, strings.ToLower(ref.Raw))
+	}
+	sort.Strings(groups)
+	return strings.Join([]string{
+		strings.ToLower(strings.Join(strings.Fields(l.Title), " ")),
+		l.LessonType.String(),
+		strings.Join(groups, ","),
+		strconv.Itoa(l.SubgroupNumber),
+	}, "|")
+}
+
+// teacherName returns the name of the teacher of the lesson as the API wrote it if the teacher is not resolved.
+func (l Lesson) teacherName() string {
+	if l.Teacher.ShortName != "" {
+		return l.Teacher.ShortName
+	}
+	return l.TeacherRef.Raw
+}
+
+// teacherNames returns the names of the teacher and the co-teachers of the lesson.
+func (l Lesson) teacherNames() []string {
+	var names []string
+	if name := l.teacherName(); name != "" {
+		names = append(names, name)
+	}
+	for _, ref := range l.AdditionalTeacherRefs {
+		if ref.Raw != "" {
+			names = append(names, ref.Raw)
+		}
+	}
+	return names
+}
+
+// roomName returns the name of the room of the lesson as the API wrote it if the room is not resolved.
+func (l Lesson) roomName() string {
+	if l.Room.FullName != "" {
+		return l.Room.FullName
+	}
+	return l.RoomRef.Raw
+}
+
+// maxMoveDistance is how far a lesson can be moved and still be matched by Diff.
+const maxMoveDistance = 6 * 24 * time.Hour
+
+// Diff returns the changes between the old and the new snapshot of a timetable.
+// Lessons are matched by their title, type, groups and subgroup, first at the same time and then
+// to the nearest lesson less than a week away,
+// so a lesson moved to another time is a ChangeMoved and not a removed and an added lesson.
+// A matched lesson can have several changes, e.g. a new room and a new teacher.
+func Diff(old, new []Lesson) []Change {
+	// Sort the lessons by time, so the moved lessons are matched in order.
+	byTime := func(lessons []Lesson) []Lesson {
+		lessons = append([]Lesson(nil), lessons...)
+		sort.SliceStable(lessons, func(i, j int) bool { return lessons[i].StartTime.Before(lessons[j].StartTime) })
+		return lessons
+	}
+	old, new = byTime(old), byTime(new)
+	oldMatched := make([]bool, len(old))
+	newMatched := make([]bool, len(new))
+
+	var changes []Change
+	// match pairs every old lesson with the new lesson of the same identity nearest to it in time,
+	// at most maxDistance away.
+	match := func(maxDistance time.Duration) {
+		for i, o := range old {
+			if oldMatched[i] {
+				continue
+			}
+			best := -1
+			var bestDistance time.Duration
+			for j, n := range new {
+				if newMatched[j] || o.identity() != n.identity() {
+					continue
+				}
+				distance := n.StartTime.Sub(o.StartTime)
+				if distance < 0 {
+					distance = -distance
+				}
+				if distance > maxDistance || best >= 0 && distance >= bestDistance {
+					continue
+				}
+				best, bestDistance = j, distance
+			}
+			if best < 0 {
+				continue
+			}
+			oldMatched[i], newMatched[best] = true, true
+			changes = append(changes, compareLessons(o, new[best])...)
+		}
+	}
+	match(0)
+	// A lesson is only moved within a week: a lesson cancelled on Monday and an extra one two weeks later
+	// are a removed and an added lesson.
+	match(maxMoveDistance)
+
+	// The rest of the lessons are removed or added.
+	for i, o := range old {
+		if !oldMatched[i] {
+			changes = append(changes, Change{Kind: ChangeRemoved, Old: o})
+		}
+	}
+	for j, n := range new {
+		if !newMatched[j] {
+			changes = append(changes, Change{Kind: ChangeAdded, New: n})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].time().Before(changes[j].time()) })
+	return changes
+}
+
+// compareLessons returns the changes of a lesson matched in both snapshots.
+func compareLessons(o, n Lesson) []Change {
+	var changes []Change
+	if !o.StartTime.Equal(n.StartTime) || !o.EndTime.Equal(n.EndTime) || !sameName(o.roomName(), n.roomName()) {
+		changes = append(changes, Change{Kind: ChangeMoved, Old: o, New: n})
+	}
+	if !sameNames(o.teacherNames(), n.teacherNames()) {
+		changes = append(changes, Change{Kind: ChangeTeacherChanged, Old: o, New: n})
+	}
+	if n.Replacement.Kind != ReplacementNone && o.Replacement.Text != n.Replacement.Text {
+		changes = append(changes, Change{Kind: ChangeReplacementAdded, Old: o, New: n})
+	}
+	return changes
+}
+
+// time returns the time the change is sorted by: the time of the new lesson, or of the old one if it was removed.
+func (c Change) time() time.Time {
+	if c.Kind == ChangeRemoved {
+		return c.Old.StartTime
+	}
+	return c.New.StartTime
+}
+
+// Ukrainian returns a description of the change in Ukrainian, e.g.
+// "Перенесено: Комп‘ютерні мережі (Лаб) з 16.10.2023, 1 пара (09:00), ауд. 320/№1 на 17.10.2023, 2 пара (10:30), ауд. 101/№1".
+func (c Change) Ukrainian() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return "Додано: " + describeLesson(c.New)
+	case ChangeRemoved:
+		return "Скасовано: " + describeLesson(c.Old)
+	case ChangeMoved:
+		return fmt.Sprintf("Перенесено: %s з %s на %s", lessonTitle(c.New), describePlace(c.Old), describePlace(c.New))
+	case ChangeTeacherChanged:
+		return fmt.Sprintf("Заміна викладача: %s, %s: %s → %s", lessonTitle(c.New), describeTime(c.New), orDash(strings.Join(c.Old.teacherNames(), ", ")), orDash(strings.Join(c.New.teacherNames(), ", ")))
+	default:
+		text := c.New.Replacement.Text
+		if text == "" {
+			text = c.New.Replacement.Title
+		}
+		return fmt.Sprintf("Заміна: %s, %s: %s", lessonTitle(c.New), describeTime(c.New), text)
+	}
+}
+
+// Summary renders the changes in Ukrainian, one per line.
+func Summary(changes []Change) string {
+	if len(changes) == 0 {
+		return "Змін немає."
+	}
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.Ukrainian()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lessonTitle returns the title of the lesson with its type, e.g. "Комп‘ютерні мережі (Лаб)".
+func lessonTitle(l Lesson) string {
+	title := l.Title
+	if title == "" {
+		title = "заняття"
+	}
+	if l.Type != "" {
+		title += " (" + l.Type + ")"
+	}
+	return title
+}
+
+// describeTime returns the day, the number and the time of the lesson, e.g. "16.10.2023, 1 пара (09:00)".
+func describeTime(l Lesson) string {
+	s := l.StartTime.Format("02.01.2006")
+	if l.Number != 0 {
+		s += ", " + strconv.Itoa(l.Number) + " пара"
+	}
+	return s + " (" + l.StartTime.Format("15:04") + ")"
+}
+
+// describePlace returns the time and the room of the lesson.
+func describePlace(l Lesson) string {
+	s := describeTime(l)
+	if room := l.roomName(); room != "" {
+		s += ", ауд. " + room
+	}
+	return s
+}
+
+// describeLesson returns the title, the time, the room and the teacher of the lesson.
+func describeLesson(l Lesson) string {
+	s := lessonTitle(l) + ", " + describePlace(l)
+	if teacher := l.teacherName(); teacher != "" {
+		s += ", " + teacher
+	}
+	return s
+}
+
+// orDash returns s, or a dash if it is empty.
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
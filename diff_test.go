@@ -0,0 +1,127 @@
+package psrozklad
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	at := func(d, h, m int) time.Time {
+		return time.Date(2023, time.October, d, h, m, 0, 0, Kyiv)
+	}
+	gorobets := Teacher{ShortName: "Горобець С.М.", Id: 420}
+	yatsenko := Teacher{ShortName: "Яценко О.С.", Id: 486}
+	room320 := Room{Name: "320", Block: "№1", FullName: "320/№1", Id: 36}
+	room101 := Room{Name: "101", Block: "№1", FullName: "101/№1", Id: 37}
+	lesson := func(title string, d, n, h, m int, teacher Teacher, room Room) Lesson {
+		return Lesson{
+			Title:      title,
+			Type:       "Лаб",
+			LessonType: LessonLab,
+			Number:     n,
+			Teacher:    teacher,
+			Room:       room,
+			GroupRefs:  []Ref{{Raw: "22Бд-СОмат", Resolved: true}},
+			StartTime:  at(d, h, m),
+			EndTime:    at(d, h, m).Add(80 * time.Minute),
+		}
+	}
+	networks := lesson("Мережі", 16, 1, 9, 0, gorobets, room320)
+	graphics := lesson("Графіка", 16, 2, 10, 30, gorobets, room320)
+	databases := lesson("Бази даних", 16, 3, 12, 20, yatsenko, room101)
+
+	moved := lesson("Мережі", 17, 2, 10, 30, gorobets, room101)
+	replaced := graphics
+	replaced.Teacher = yatsenko
+	replaced.Replacement = Replacement{Kind: ReplacementTeacher, Teacher: yatsenko, Text: "Увага! Заміна! Яценко О.С."}
+	added := lesson("Алгоритми", 18, 1, 9, 0, yatsenko, room320)
+
+	got := Diff([]Lesson{databases, graphics, networks}, []Lesson{added, replaced, moved})
+	want := []Change{
+		{Kind: ChangeTeacherChanged, Old: graphics, New: replaced},
+		{Kind: ChangeReplacementAdded, Old: graphics, New: replaced},
+		{Kind: ChangeRemoved, Old: databases},
+		{Kind: ChangeMoved, Old: networks, New: moved},
+		{Kind: ChangeAdded, New: added},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want: \n%v\ngot: \n%v", want, got)
+	}
+
+	wantSummary := "Заміна викладача: Графіка (Лаб), 16.10.2023, 2 пара (10:30): Горобець С.М. → Яценко О.С.\n" +
+		"Заміна: Графіка (Лаб), 16.10.2023, 2 пара (10:30): Увага! Заміна! Яценко О.С.\n" +
+		"Скасовано: Бази даних (Лаб), 16.10.2023, 3 пара (12:20), ауд. 101/№1, Яценко О.С.\n" +
+		"Перенесено: Мережі (Лаб) з 16.10.2023, 1 пара (09:00), ауд. 320/№1 на 17.10.2023, 2 пара (10:30), ауд. 101/№1\n" +
+		"Додано: Алгоритми (Лаб), 18.10.2023, 1 пара (09:00), ауд. 320/№1, Яценко О.С."
+	if s := Summary(got); s != wantSummary {
+		t.Errorf("want: \n%s\ngot: \n%s", wantSummary, s)
+	}
+
+	if changes := Diff([]Lesson{networks, graphics}, []Lesson{graphics, networks}); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+	if s := Summary(nil); s != "Змін немає." {
+		t.Errorf("unexpected summary: %s", s)
+	}
+}
+
+func TestDiffFarLessons(t *testing.T) {
+	at := func(d, h, m int) time.Time {
+		return time.Date(2023, time.October, d, h, m, 0, 0, Kyiv)
+	}
+	lesson := func(d, h int, teachers ...string) Lesson {
+		l := Lesson{
+			Title:      "Мережі",
+			Type:       "Лаб",
+			LessonType: LessonLab,
+			TeacherRef: Ref{Raw: teachers[0]},
+			GroupRefs:  []Ref{{Raw: "22Бд-СОмат", Resolved: true}},
+			StartTime:  at(d, h, 0),
+			EndTime:    at(d, h, 0).Add(80 * time.Minute),
+		}
+		for _, teacher := range teachers[1:] {
+			l.AdditionalTeacherRefs = append(l.AdditionalTeacherRefs, Ref{Raw: teacher})
+		}
+		return l
+	}
+
+	// A lesson cancelled on Monday and an extra one two weeks later are not a moved lesson.
+	cancelled := lesson(16, 9, "Горобець С.М.")
+	extra := lesson(30, 9, "Горобець С.М.")
+	got := Diff([]Lesson{cancelled}, []Lesson{extra})
+	want := []Change{
+		{Kind: ChangeRemoved, Old: cancelled},
+		{Kind: ChangeAdded, New: extra},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: \n%v\ngot: \n%v", want, got)
+	}
+
+	// A moved lesson is matched to the nearest lesson.
+	near, far := lesson(17, 9, "Горобець С.М."), lesson(20, 9, "Горобець С.М.")
+	got = Diff([]Lesson{cancelled}, []Lesson{far, near})
+	want = []Change{
+		{Kind: ChangeMoved, Old: cancelled, New: near},
+		{Kind: ChangeAdded, New: far},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: \n%v\ngot: \n%v", want, got)
+	}
+
+	// A new co-teacher is a changed teacher.
+	old := lesson(16, 9, "Горобець С.М.", "Яценко О.С.")
+	new := lesson(16, 9, "Горобець С.М.", "Іваненко І.І.")
+	got = Diff([]Lesson{old}, []Lesson{new})
+	want = []Change{{Kind: ChangeTeacherChanged, Old: old, New: new}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: \n%v\ngot: \n%v", want, got)
+	}
+	wantText := "Заміна викладача: Мережі (Лаб), 16.10.2023 (09:00): Горобець С.М., Яценко О.С. → Горобець С.М., Іваненко І.І."
+	if len(got) == 1 && got[0].Ukrainian() != wantText {
+		t.Errorf("want: %s\ngot: %s", wantText, got[0].Ukrainian())
+	}
+	if changes := Diff([]Lesson{old}, []Lesson{lesson(16, 9, "Горобець С.М.", "яценко о.с.")}); len(changes) != 0 {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
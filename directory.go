@@ -0,0 +1,148 @@
+package psrozklad
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directory holds the rooms, teachers and groups known to an Api.
+// Every Api owns its own Directory, so several clients can be used in one process.
+type Directory struct {
+	mu sync.RWMutex
+
+	rooms    map[string]Room
+	teachers map[string]Teacher
+	groups   map[string]Group
+
+	roomsByID    map[int]Room
+	teachersByID map[int]Teacher
+	groupsByID   map[int]Group
+
+	// refreshed holds the time of the last refresh of every list after a miss.
+	refreshed map[string]time.Time
+}
+
+// NewDirectory creates a new empty Directory.
+func NewDirectory() *Directory {
+	return &Directory{
+		rooms:        make(map[string]Room),
+		teachers:     make(map[string]Teacher),
+		groups:       make(map[string]Group),
+		roomsByID:    make(map[int]Room),
+		teachersByID: make(map[int]Teacher),
+		groupsByID:   make(map[int]Group),
+	}
+}
+
+// roomKey returns the key under which the room is stored, e.g. "320/№1".
+func roomKey(room Room) string {
+	return strings.ToLower(room.Name + "/" + room.Block)
+}
+
+// SetRooms replaces all rooms in the directory.
+func (d *Directory) SetRooms(rooms []Room) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rooms = make(map[string]Room, len(rooms))
+	d.roomsByID = make(map[int]Room, len(rooms))
+	for _, room := range rooms {
+		// The key of each room is the room's name and block, concatenated with a slash.
+		d.rooms[roomKey(room)] = room
+		d.roomsByID[room.Id] = room
+	}
+}
+
+// SetGroups replaces all groups in the directory.
+func (d *Directory) SetGroups(groups []Group) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.groups = make(map[string]Group, len(groups))
+	d.groupsByID = make(map[int]Group, len(groups))
+	for _, group := range groups {
+		// The key of each group is the group's name.
+		d.groups[strings.ToLower(group.Name)] = group
+		d.groupsByID[group.Id] = group
+	}
+}
+
+// SetTeachers replaces all teachers in the directory.
+func (d *Directory) SetTeachers(teachers []Teacher) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.teachers = make(map[string]Teacher, len(teachers))
+	d.teachersByID = make(map[int]Teacher, len(teachers))
+	for _, teacher := range teachers {
+		// The key of each teacher is the teacher's short name.
+		d.teachers[strings.ToLower(teacher.ShortName)] = teacher
+		d.teachersByID[teacher.Id] = teacher
+	}
+}
+
+// Room looks up a room by its key ("name/block"), case-insensitively.
+func (d *Directory) Room(key string) (Room, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	room, ok := d.rooms[strings.ToLower(key)]
+	return room, ok
+}
+
+// RoomByID looks up a room by its ID.
+func (d *Directory) RoomByID(id int) (Room, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	room, ok := d.roomsByID[id]
+	return room, ok
+}
+
+// Group looks up a group by its name, case-insensitively.
+func (d *Directory) Group(key string) (Group, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	group, ok := d.groups[strings.ToLower(key)]
+	return group, ok
+}
+
+// GroupByID looks up a group by its ID.
+func (d *Directory) GroupByID(id int) (Group, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	group, ok := d.groupsByID[id]
+	return group, ok
+}
+
+// Teacher looks up a teacher by the short name, e.g. "Горобець С.М.", case-insensitively.
+func (d *Directory) Teacher(key string) (Teacher, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	teacher, ok := d.teachers[strings.ToLower(key)]
+	return teacher, ok
+}
+
+// TeacherByID looks up a teacher by its ID.
+func (d *Directory) TeacherByID(id int) (Teacher, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	teacher, ok := d.teachersByID[id]
+	return teacher, ok
+}
+
+// startRefresh reports whether the list of the kind may be refreshed after a miss,
+// which is when it was not refreshed during the last minInterval. If so, the refresh is recorded.
+func (d *Directory) startRefresh(kind string, minInterval time.Duration) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.refreshed == nil {
+		d.refreshed = make(map[string]time.Time)
+	}
+	now := time.Now()
+	if last, ok := d.refreshed[kind]; ok && now.Sub(last) < minInterval {
+		return false
+	}
+	d.refreshed[kind] = now
+	return true
+}
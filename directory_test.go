@@ -0,0 +1,91 @@
+package psrozklad
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestDirectoryLookup(t *testing.T) {
+	d := NewDirectory()
+	d.SetRooms([]Room{{Block: "№1", Name: "320", FullName: "320/№1", Id: 36}})
+	d.SetGroups([]Group{{Name: "21Бд-СОмат", Id: 11}})
+	d.SetTeachers([]Teacher{{ShortName: "Горобець С.М.", Id: 420}})
+
+	if room, ok := d.Room("320/№1"); !ok || room.Id != 36 {
+		t.Errorf("room by key: got %v, %v", room, ok)
+	}
+	if room, ok := d.RoomByID(36); !ok || room.Name != "320" {
+		t.Errorf("room by id: got %v, %v", room, ok)
+	}
+	if group, ok := d.Group("21бд-сомат"); !ok || group.Id != 11 {
+		t.Errorf("group by key: got %v, %v", group, ok)
+	}
+	if group, ok := d.GroupByID(11); !ok || group.Name != "21Бд-СОмат" {
+		t.Errorf("group by id: got %v, %v", group, ok)
+	}
+	if teacher, ok := d.Teacher("горобець с.м."); !ok || teacher.Id != 420 {
+		t.Errorf("teacher by key: got %v, %v", teacher, ok)
+	}
+	if teacher, ok := d.TeacherByID(420); !ok || teacher.ShortName != "Горобець С.М." {
+		t.Errorf("teacher by id: got %v, %v", teacher, ok)
+	}
+	if _, ok := d.Teacher("Яценко О.С."); ok {
+		t.Errorf("unexpected teacher found")
+	}
+}
+
+func TestDirectoryIsolation(t *testing.T) {
+	groupsJson := func(name, id string) string {
+		return `{"psrozklad_export": {"departments": [{"name": "ФМФ", "objects": [{"name": "` + name + `", "ID": "` + id + `"}]}], "code": "0"}}`
+	}
+
+	first := newTestApi(t)
+	first.HttpClient = &MockHttpClient{resp: http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(groupsJson("21Бд-СОмат", "11"))),
+	}}
+	second := newTestApi(t)
+	second.HttpClient = &MockHttpClient{resp: http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(groupsJson("31Бд-Інф", "21"))),
+	}}
+
+	if err := first.InitGroups(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := second.InitGroups(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := first.Directory.Group("21Бд-СОмат"); !ok {
+		t.Errorf("first api lost its group")
+	}
+	if _, ok := first.Directory.Group("31Бд-Інф"); ok {
+		t.Errorf("first api sees groups of the second api")
+	}
+	if _, ok := second.Directory.Group("21Бд-СОмат"); ok {
+		t.Errorf("second api sees groups of the first api")
+	}
+}
+
+func TestDirectoryCreatedOnce(t *testing.T) {
+	// A zero Api creates its directory on first use, also when it is used from several goroutines.
+	var api Api
+	dirs := make(chan *Directory, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(dirs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			dirs <- api.dir()
+		}()
+	}
+	wg.Wait()
+	close(dirs)
+	for d := range dirs {
+		if d == nil || d != api.Directory {
+			t.Fatalf("unexpected directory %p, want %p", d, api.Directory)
+		}
+	}
+}
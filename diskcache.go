@@ -0,0 +1,162 @@
+package psrozklad
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StaleError is returned together with data served from the DiskCache when the API is unreachable.
+// The data is the last known data, fetched at FetchedAt.
+type StaleError struct {
+	FetchedAt time.Time
+	// Err is the error that made the API unreachable.
+	Err error
+}
+
+func (e *StaleError) Error() string {
+	return fmt.Sprintf("serving stale data fetched at %s: %v", e.FetchedAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *StaleError) Unwrap() error {
+	return e.Err
+}
+
+// IsStale reports whether err is a *StaleError, which means that the returned data is usable but stale.
+func IsStale(err error) bool {
+	var stale *StaleError
+	return errors.As(err, &stale)
+}
+
+// staleError returns err if it is a *StaleError, or nil otherwise.
+func staleError(err error) error {
+	if IsStale(err) {
+		return err
+	}
+	return nil
+}
+
+// DiskCache keeps raw API responses and parsed lessons in a directory, so they survive restarts.
+type DiskCache struct {
+	// Dir is the directory where the entries are stored.
+	Dir string
+	// MaxAge is how long entries are served without asking the API. Zero means they are used only when the API is unreachable.
+	MaxAge time.Duration
+	// ServeStale enables the offline mode: if the API is unreachable, the last known data is returned with a *StaleError.
+	ServeStale bool
+}
+
+// NewDiskCache creates the directory dir and returns a DiskCache using it in offline mode.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+	return &DiskCache{Dir: dir, ServeStale: true}, nil
+}
+
+// diskEntry is the content of a cache file.
+type diskEntry struct {
+	Key       string          `json:"key"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Body      json.RawMessage `json:"body"`
+}
+
+// path returns the path of the file of the entry with the given key.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the body stored under key and the time it was fetched.
+func (c *DiskCache) load(key string) (json.RawMessage, time.Time, bool) {
+	if c == nil {
+		return nil, time.Time{}, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var entry diskEntry
+	// Ignore broken files and hash collisions.
+	if json.Unmarshal(data, &entry) != nil || entry.Key != key {
+		return nil, time.Time{}, false
+	}
+	return entry.Body, entry.FetchedAt, true
+}
+
+// fresh returns the body stored under key if it is younger than MaxAge.
+func (c *DiskCache) fresh(key string) (json.RawMessage, bool) {
+	if c == nil || c.MaxAge <= 0 {
+		return nil, false
+	}
+	body, fetchedAt, ok := c.load(key)
+	if !ok || time.Since(fetchedAt) > c.MaxAge {
+		return nil, false
+	}
+	return body, true
+}
+
+// stale returns the body stored under key if the offline mode is enabled.
+func (c *DiskCache) stale(key string) (json.RawMessage, time.Time, bool) {
+	if c == nil || !c.ServeStale {
+		return nil, time.Time{}, false
+	}
+	return c.load(key)
+}
+
+// store saves body under key. Failures are ignored, the cache is best effort.
+func (c *DiskCache) store(key string, body json.RawMessage) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(diskEntry{Key: key, FetchedAt: time.Now(), Body: body})
+	if err != nil {
+		return
+	}
+
+	writeFileAtomic(c.path(key), data)
+}
+
+// remove removes the entry stored under key.
+func (c *DiskCache) remove(key string) {
+	if c == nil {
+		return
+	}
+	os.Remove(c.path(key))
+}
+
+// writeFileAtomic writes data to a temporary file next to path first and renames it,
+// so that readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// unreachable reports whether err means that the API can't be reached, so stale data may be served.
+func unreachable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.Temporary()
+	}
+	var upstream *UpstreamError
+	return !errors.As(err, &upstream) && !errors.Is(err, ErrMalformedPayload)
+}
@@ -0,0 +1,134 @@
+package psrozklad
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// downHttpClient fails every request like an unreachable server.
+type downHttpClient struct {
+	calls int
+}
+
+func (d *downHttpClient) Do(req *http.Request) (*http.Response, error) {
+	d.calls++
+	return nil, errors.New("connection refused")
+}
+
+func TestDiskCacheOffline(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, time.UTC)
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+
+	// Fetch the groups and lessons while the server is up.
+	online := newTestApi(t)
+	online.DiskCache, _ = NewDiskCache(dir)
+	online.HttpClient = &countingHttpClient{body: `{"psrozklad_export": {"departments": [{"name": "ФМФ", "objects": [{"name": "21Бд-СОмат", "ID": "11"}]}], "code": "0"}}`}
+	if err := online.InitGroups(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	online.HttpClient = &countingHttpClient{body: `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "title": "Алгебра"}], "code": "0"}}`}
+	if _, err := online.GetLessons(group, day, day); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// After a restart the server is down.
+	offline := newTestApi(t)
+	offline.DiskCache, _ = NewDiskCache(dir)
+	offline.HttpClient = &downHttpClient{}
+
+	groups, err := offline.GetGroups()
+	var stale *StaleError
+	if !errors.As(err, &stale) {
+		t.Fatalf("want *StaleError, got: %v", err)
+	}
+	if stale.FetchedAt.IsZero() || len(groups) != 1 || groups[0].Id != 11 {
+		t.Errorf("unexpected stale groups: %v, %v", groups, stale)
+	}
+
+	lessons, err := offline.GetLessons(group, day, day)
+	if !IsStale(err) {
+		t.Fatalf("want *StaleError, got: %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].Title != "Алгебра" || lessons[0].Groups[0].Id != 11 {
+		t.Errorf("unexpected stale lessons: %v", lessons)
+	}
+
+	// Without the offline mode the error is returned.
+	offline.DiskCache.ServeStale = false
+	if _, err := offline.GetGroups(); err == nil || IsStale(err) {
+		t.Errorf("want error, got: %v", err)
+	}
+}
+
+func TestDiskCacheMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	client := &countingHttpClient{body: `{"psrozklad_export": {"blocks": [{"name": "№1", "objects": [{"name": "320/№1", "ID": "36"}]}], "code": "0"}}`}
+	api := newTestApi(t)
+	api.HttpClient = client
+	api.DiskCache, _ = NewDiskCache(dir)
+	api.DiskCache.MaxAge = time.Hour
+
+	for i := 0; i < 2; i++ {
+		rooms, err := api.GetRooms()
+		if err != nil || len(rooms) != 1 {
+			t.Fatalf("unexpected result: %v, %v", rooms, err)
+		}
+	}
+	if client.calls != 1 {
+		t.Errorf("want 1 request, got %d", client.calls)
+	}
+}
+
+func TestDiskCacheUpstreamError(t *testing.T) {
+	api := newTestApi(t)
+	api.HttpClient = &countingHttpClient{body: `{"psrozklad_export": {"code": "-90", "error": "Помилка"}}`}
+	api.DiskCache, _ = NewDiskCache(t.TempDir())
+
+	// Error responses are neither stored nor served stale.
+	var upstream *UpstreamError
+	if _, err := api.GetGroups(); !errors.As(err, &upstream) {
+		t.Errorf("want *UpstreamError, got: %v", err)
+	}
+	api.HttpClient = &downHttpClient{}
+	if _, err := api.GetGroups(); err == nil || IsStale(err) {
+		t.Errorf("want error, got: %v", err)
+	}
+}
+
+func TestDiskCacheLessons(t *testing.T) {
+	dir := t.TempDir()
+	client := &countingHttpClient{body: `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20"}], "code": "0"}}`}
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+
+	// Store the lessons on disk with one Api, then read them with another.
+	first := newTestApi(t, WithHTTPClient(client))
+	first.DiskCache, _ = NewDiskCache(dir)
+	first.DiskCache.MaxAge = time.Hour
+	if _, err := first.GetLessons(group, day, day); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second := newTestApi(t, WithHTTPClient(client), WithCache(NewCache(DefaultCacheConfig)))
+	second.DiskCache = first.DiskCache
+	for i := 0; i < 2; i++ {
+		lessons, err := second.GetLessons(group, day, day)
+		if err != nil || len(lessons) != 1 {
+			t.Fatalf("unexpected result: %v, %v", lessons, err)
+		}
+		// The times are in the zone of the Api, not in a fixed offset.
+		if loc := lessons[0].StartTime.Location(); loc != Kyiv || lessons[0].EndTime.Location() != Kyiv {
+			t.Errorf("unexpected location: %v", loc)
+		}
+	}
+	if client.calls != 1 {
+		t.Errorf("want 1 request, got %d", client.calls)
+	}
+	// The disk hit fills the memory cache.
+	if stats := second.CacheStats(); stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected cache stats: %+v", stats)
+	}
+}
@@ -0,0 +1,41 @@
+package psrozklad
+
+import (
+	"unicode/utf8"
+)
+
+// Encoding is the coding_mode the API is asked to respond in.
+type Encoding string
+
+const (
+	EncodingUTF8        Encoding = "UTF8"
+	EncodingWindows1251 Encoding = "WINDOWS-1251"
+)
+
+// windows1251 maps the bytes 0x80-0xBF of Windows-1251 to runes. 0xC0-0xFF map to А-я.
+var windows1251 = [64]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', utf8.RuneError, '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00a0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00ad', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+}
+
+// decode converts body from the encoding to UTF-8.
+func (e Encoding) decode(body []byte) []byte {
+	if e != EncodingWindows1251 {
+		return body
+	}
+
+	out := make([]byte, 0, len(body)*2)
+	for _, b := range body {
+		switch {
+		case b < 0x80:
+			out = append(out, b)
+		case b < 0xC0:
+			out = utf8.AppendRune(out, windows1251[b-0x80])
+		default:
+			out = utf8.AppendRune(out, 'А'+rune(b-0xC0))
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package psrozklad
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMalformedPayload is returned when the response of the API can't be decoded.
+	ErrMalformedPayload = errors.New("malformed payload")
+
+	// ErrUnknownObject is returned for a nil object and wrapped by the *UpstreamError of an object not known to the API.
+	ErrUnknownObject = errors.New("unknown object")
+
+	// ErrDateParse is returned when a date or time of a lesson can't be parsed.
+	ErrDateParse = errors.New("failed to parse date")
+
+	// ErrReplacementParse is returned when a replacement notice can't be parsed.
+	ErrReplacementParse = errors.New("unrecognized replacement notice")
+
+	// ErrNameParse is returned when a full name of a teacher can't be parsed.
+	ErrNameParse = errors.New("failed to parse name")
+
+	// ErrNoBellSchedule is returned by the searches aligned to the lesson slots when the Api has no Bells.
+	ErrNoBellSchedule = errors.New("no bell schedule")
+
+	// ErrWatcherRunning is returned by Run of a Watcher that was already run.
+	ErrWatcherRunning = errors.New("watcher already run")
+)
+
+// UpstreamError is returned when the API reports an error in the code field of psrozklad_export.
+type UpstreamError struct {
+	Code    string
+	Message string
+}
+
+func (e *UpstreamError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("upstream error code %s", e.Code)
+	}
+	return fmt.Sprintf("upstream error code %s: %s", e.Code, e.Message)
+}
+
+// unknownObjectCodes are the codes the API answers with when the requested object does not exist.
+var unknownObjectCodes = map[string]bool{
+	"-90": true,
+}
+
+// Unwrap returns ErrUnknownObject if the API reports that the object does not exist, so errors.Is matches it.
+func (e *UpstreamError) Unwrap() error {
+	if unknownObjectCodes[e.Code] || strings.Contains(strings.ToLower(e.Message), "не знайдено") {
+		return ErrUnknownObject
+	}
+	return nil
+}
+
+// StatusError is returned when the API responds with a non-200 HTTP status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected http status: %s", e.Status)
+}
+
+// Temporary reports whether the request may succeed if it is repeated later.
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// exportStatus is embedded in every psrozklad_export struct to decode the status of the response.
+type exportStatus struct {
+	Code  string          `json:"code"`
+	Error json.RawMessage `json:"error"`
+}
+
+// err returns an *UpstreamError if the export reports an error, or nil otherwise.
+func (s exportStatus) err() error {
+	// An empty code or "0" means success.
+	if s.Code == "" || s.Code == "0" {
+		return nil
+	}
+
+	// The error message may be either a string or an object with an error_message field.
+	var message string
+	if json.Unmarshal(s.Error, &message) != nil {
+		var obj struct {
+			Message string `json:"error_message"`
+		}
+		if json.Unmarshal(s.Error, &obj) == nil {
+			message = obj.Message
+		}
+	}
+
+	return &UpstreamError{Code: s.Code, Message: message}
+}
@@ -0,0 +1,90 @@
+package psrozklad
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	newApi := func(status int, body string) *Api {
+		api := newTestApi(t)
+		api.HttpClient = &MockHttpClient{resp: http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}}
+		return api
+	}
+
+	t.Run("upstream error", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{"psrozklad_export": {"code": "-90", "error": {"error_message": "Об'єкт не знайдено"}}}`)
+		_, err := api.GetGroups()
+		var upstream *UpstreamError
+		if !errors.As(err, &upstream) {
+			t.Fatalf("want *UpstreamError, got: %v", err)
+		}
+		if upstream.Code != "-90" || upstream.Message != "Об'єкт не знайдено" {
+			t.Errorf("unexpected upstream error: %#v", upstream)
+		}
+		if !errors.Is(err, ErrUnknownObject) {
+			t.Errorf("want ErrUnknownObject, got: %v", err)
+		}
+	})
+
+	t.Run("other upstream error", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{"psrozklad_export": {"code": "-1", "error": "Помилка бази даних"}}`)
+		_, err := api.GetLessons(Group{Id: 11}, time.Time{}, time.Time{})
+		var upstream *UpstreamError
+		if !errors.As(err, &upstream) || errors.Is(err, ErrUnknownObject) {
+			t.Errorf("want a plain *UpstreamError, got: %v", err)
+		}
+	})
+
+	t.Run("unknown object code", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{"psrozklad_export": {"code": "-90"}}`)
+		_, err := api.GetLessons(Group{Id: 999999}, time.Time{}, time.Time{})
+		if !errors.Is(err, ErrUnknownObject) {
+			t.Errorf("want ErrUnknownObject, got: %v", err)
+		}
+	})
+
+	t.Run("http status", func(t *testing.T) {
+		api := newApi(http.StatusServiceUnavailable, `<html></html>`)
+		_, err := api.GetRooms()
+		var status *StatusError
+		if !errors.As(err, &status) {
+			t.Fatalf("want *StatusError, got: %v", err)
+		}
+		if status.StatusCode != http.StatusServiceUnavailable || !status.Temporary() {
+			t.Errorf("unexpected status error: %#v", status)
+		}
+	})
+
+	t.Run("malformed payload", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{"psrozklad_export": `)
+		_, err := api.GetTeachers()
+		if !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("want ErrMalformedPayload, got: %v", err)
+		}
+	})
+
+	t.Run("unknown object", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{}`)
+		_, err := api.GetLessons(nil, time.Time{}, time.Time{})
+		if !errors.Is(err, ErrUnknownObject) {
+			t.Errorf("want ErrUnknownObject, got: %v", err)
+		}
+	})
+
+	t.Run("date parse", func(t *testing.T) {
+		api := newApi(http.StatusOK, `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10", "lesson_number": "1", "lesson_time": "09:00-10:20"}], "code": "0"}}`)
+		_, err := api.GetLessons(Group{}, time.Time{}, time.Time{})
+		if !errors.Is(err, ErrDateParse) {
+			t.Errorf("want ErrDateParse, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package psrozklad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RoomFilter selects the rooms FindFreeRooms looks at.
+type RoomFilter struct {
+	// Block is the block of the rooms, e.g. "№1". Empty means all blocks.
+	Block string
+	// Match selects rooms by anything else, e.g. the name. Nil means all rooms.
+	Match func(Room) bool
+	// Concurrency is the maximum number of rooms fetched at once, 4 if zero.
+	Concurrency int
+}
+
+// match reports whether the filter selects the room.
+func (f RoomFilter) match(room Room) bool {
+	if f.Block != "" && !strings.EqualFold(strings.TrimSpace(room.Block), strings.TrimSpace(f.Block)) {
+		return false
+	}
+	return f.Match == nil || f.Match(room)
+}
+
+// overlaps reports whether the periods [aStart, aEnd) and [bStart, bEnd) have some time in common.
+func overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// FindFreeRooms returns the rooms selected by the filter that have no lessons and no reservations between from and to.
+// The timetables of the rooms are fetched concurrently and go through the caches of the Api.
+// If some data is stale, the free rooms are returned with a *StaleError.
+func (a *Api) FindFreeRooms(ctx context.Context, from, to time.Time, filter RoomFilter) ([]Room, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period: %v - %v", from, to)
+	}
+
+	// Get the rooms to check.
+	rooms, err := a.GetRoomsContext(ctx)
+	firstStale := staleError(err)
+	if err != nil && firstStale == nil {
+		return nil, err
+	}
+	var selected []Room
+	for _, room := range rooms {
+		if filter.match(room) {
+			selected = append(selected, room)
+		}
+	}
+
+	// Fetch the timetables of the rooms.
+	objs := make([]Object, len(selected))
+	for i, room := range selected {
+		objs[i] = room
+	}
+	tts, err := a.getTimetables(ctx, objs, from, to, filter.Concurrency)
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	if firstStale == nil {
+		firstStale = staleError(err)
+	}
+
+	// Collect the free rooms in a stable order.
+	var found []Room
+	for i, room := range selected {
+		if !tts[i].busy(from, to) {
+			found = append(found, room)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Block != found[j].Block {
+			return found[i].Block < found[j].Block
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found, firstStale
+}
+
+// busy reports whether the timetable has a lesson or a reservation between from and to.
+func (tt timetable) busy(from, to time.Time) bool {
+	for _, lesson := range tt.Lessons {
+		if overlaps(lesson.StartTime, lesson.EndTime, from, to) {
+			return true
+		}
+	}
+	for _, res := range tt.Reservations {
+		if overlaps(res.StartTime, res.EndTime, from, to) {
+			return true
+		}
+	}
+	return false
+}
+
+// getTimetables gets the timetables of the objects concurrently, at most concurrency at once (4 if zero).
+// It stops at the first error. If some data is stale, the timetables are returned with the first *StaleError.
+func (a *Api) getTimetables(ctx context.Context, objs []Object, from, to time.Time, concurrency int) ([]timetable, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	sem := make(chan struct{}, concurrency)
+	tts := make([]timetable, len(objs))
+	var (
+		wg                   sync.WaitGroup
+		mu                   sync.Mutex
+		firstErr, firstStale error
+	)
+	for i, obj := range objs {
+		wg.Add(1)
+		go func(i int, obj Object) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			tt, err := a.getTimetable(ctx, obj, from, to)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil && !IsStale(err):
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to get lessons of %s %d: %w", obj.type_obj(), obj.ID(), err)
+					cancel()
+				}
+				return
+			case err != nil && firstStale == nil:
+				firstStale = err
+			}
+			tts[i] = tt
+		}(i, obj)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// Objects skipped because the caller canceled have no timetable.
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	return tts, firstStale
+}
@@ -0,0 +1,84 @@
+package psrozklad
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedHttpClient serializes the requests to a client that is not safe for concurrent use.
+type lockedHttpClient struct {
+	mu     sync.Mutex
+	client Client
+}
+
+func (l *lockedHttpClient) Do(req *http.Request) (*http.Response, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.client.Do(req)
+}
+
+func TestFindFreeRooms(t *testing.T) {
+	lessonsOf := func(items string) string {
+		return `{"psrozklad_export": {"roz_items": [` + items + `], "code": "0"}}`
+	}
+	routes := &routeHttpClient{routes: []route{
+		{"req_type=obj_list&req_mode=room", `{"psrozklad_export": {"blocks": [
+			{"name": "№1", "objects": [{"name": "320/№1", "ID": "36"}, {"name": "101/№1", "ID": "37"}, {"name": "102/№1", "ID": "38"}]},
+			{"name": "№2", "objects": [{"name": "5/№2", "ID": "50"}]}
+		], "code": "0"}}`},
+		{"OBJ_ID=36&", lessonsOf(`{"object": "320/№1", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "title": "Графіка"}`)},
+		{"OBJ_ID=37&", lessonsOf(`{"object": "101/№1", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50", "reservation": "Засідання кафедри"}`)},
+		{"OBJ_ID=38&", lessonsOf(`{"object": "102/№1", "date": "16.10.2023", "lesson_number": "3", "lesson_time": "12:20-13:40", "title": "Мережі"}`)},
+		{"OBJ_ID=50&", lessonsOf(``)},
+	}}
+	api := newTestApi(t, WithHTTPClient(&lockedHttpClient{client: routes}), WithCache(NewCache(DefaultCacheConfig)))
+	day := func(h, m int) time.Time {
+		return time.Date(2023, time.October, 16, h, m, 0, 0, Kyiv)
+	}
+
+	testCases := []struct {
+		desc     string
+		from, to time.Time
+		filter   RoomFilter
+		want     []string
+	}{
+		{"lesson and reservation", day(9, 30), day(11, 0), RoomFilter{Block: "№1"}, []string{"102/№1"}},
+		{"touching periods", day(10, 20), day(10, 30), RoomFilter{Block: "№1"}, []string{"101/№1", "102/№1", "320/№1"}},
+		{"all blocks", day(12, 0), day(13, 0), RoomFilter{Concurrency: 1}, []string{"101/№1", "320/№1", "5/№2"}},
+		{"match", day(9, 0), day(18, 0), RoomFilter{Match: func(r Room) bool { return r.Name == "5" }}, []string{"5/№2"}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			rooms, err := api.FindFreeRooms(context.Background(), tC.from, tC.to, tC.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, room := range rooms {
+				got = append(got, room.FullName)
+			}
+			if !reflect.DeepEqual(got, tC.want) {
+				t.Errorf("want %v, got %v", tC.want, got)
+			}
+		})
+	}
+
+	// The timetables of the same day are fetched once.
+	if n := routes.calls["OBJ_ID=36&"]; n != 1 {
+		t.Errorf("room 36 fetched %d times", n)
+	}
+
+	// A failed room fails the search.
+	routes.routes = routes.routes[:4]
+	api.Cache.Purge()
+	_, err := api.FindFreeRooms(context.Background(), day(9, 0), day(10, 0), RoomFilter{})
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -1,9 +1,8 @@
 package psrozklad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"strconv"
 )
 
@@ -25,6 +24,11 @@ func (g Group) type_obj() string {
 
 // This function returns a list of groups from the API.
 func (a *Api) GetGroups() ([]Group, error) {
+	return a.GetGroupsContext(context.Background())
+}
+
+// GetGroupsContext is like GetGroups but uses ctx for the http request.
+func (a *Api) GetGroupsContext(ctx context.Context) ([]Group, error) {
 
 	type group struct {
 		Name string `json:"name"`
@@ -42,27 +46,21 @@ func (a *Api) GetGroups() ([]Group, error) {
 		PsrozkladExport psrozkladExport `json:"psrozklad_export"`
 	}
 
-	// Construct the URL for the API request.
-	url := a.BaseUri
-	url += "&req_type=obj_list"
-	url += "&req_mode=group"
-	url += "&show_ID=yes"
-
-	// Create a new HTTP request.
-	req, _ := http.NewRequest("GET", url, nil)
-
-	// Execute the HTTP request.
-	resp, err := a.HttpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to do http request: %v", err)
+	// Return the cached list if there is one.
+	key := listKey("group")
+	if cached, ok := a.Cache.get(key); ok {
+		return append([]Group(nil), cached.([]Group)...), nil
 	}
-	defer resp.Body.Close()
 
-	// Decode the JSON response into an export struct.
+	// Construct the URL for the API request.
+	url := a.listURL("group")
+
+	// Do the HTTP request and decode the JSON response into an export struct.
 	var exp export
-	err = json.NewDecoder(resp.Body).Decode(&exp)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode groups json: %v", err)
+	err := a.getJSON(ctx, url, &exp)
+	stale := staleError(err)
+	if err != nil && stale == nil {
+		return nil, fmt.Errorf("failed to get group list: %w", err)
 	}
 
 	// Create a slice of Group structs to store the results.
@@ -74,7 +72,7 @@ func (a *Api) GetGroups() ([]Group, error) {
 			// Convert the group ID to an integer.
 			id, err := strconv.Atoi(group.Id)
 			if err != nil {
-				return nil, fmt.Errorf("failed to convert group id to string: %v", err)
+				return nil, fmt.Errorf("%w: failed to convert group id: %v, to int: %v", ErrMalformedPayload, group.Id, err)
 			}
 
 			// Add the group to the results slice.
@@ -86,6 +84,11 @@ func (a *Api) GetGroups() ([]Group, error) {
 		}
 	}
 
+	// Store a copy of the list in the cache, unless it is stale.
+	if stale == nil {
+		a.Cache.set(key, append([]Group(nil), groups...))
+	}
+
 	// Return the results slice.
-	return groups, nil
+	return groups, stale
 }
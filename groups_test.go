@@ -23,7 +23,7 @@ func TestGetGroups(t *testing.T) {
 		},
 	}
 
-	api := New("")
+	api := newTestApi(t)
 	api.HttpClient = &MockHttpClient{resp: http.Response{
 		Body: io.NopCloser(bytes.NewBufferString(`{
 				"psrozklad_export": {
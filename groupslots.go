@@ -0,0 +1,189 @@
+package psrozklad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// GroupSlotOptions configures FindGroupSlots.
+type GroupSlotOptions struct {
+	// AllowSubgroupConflicts keeps the slots where only a subgroup of some group has a lesson.
+	// Such slots are ranked after the slots where everybody is free.
+	AllowSubgroupConflicts bool
+	// Teachers must be free in the slot too, e.g. the lecturer of the make-up lecture.
+	Teachers []Teacher
+	// Rooms, if not nil, selects the rooms one of which must be free in the slot.
+	Rooms *RoomFilter
+	// Concurrency is the maximum number of timetables fetched at once, 4 if zero.
+	Concurrency int
+}
+
+// SlotCandidate is a lesson slot when the groups can meet.
+type SlotCandidate struct {
+	Slot  Slot
+	Start time.Time
+	End   time.Time
+	// SubgroupConflicts are the subgroup lessons in the slot, empty if everybody is free.
+	SubgroupConflicts []Lesson
+	// Adjacent is the number of groups that have a lesson right before or after the slot,
+	// these slots don't leave the students with gaps.
+	Adjacent int
+	// Rooms are the free rooms if GroupSlotOptions.Rooms is set.
+	Rooms []Room
+}
+
+// FindGroupSlots returns the lesson slots between from and to when all the groups are free, best first.
+// The slots without subgroup conflicts come first, then the ones next to the lessons of more groups, then the earlier ones.
+// The timetables are fetched concurrently and go through the caches of the Api.
+// The slots are the ones of the Bells of the Api, without them ErrNoBellSchedule is returned.
+// If some data is stale, the slots are returned with a *StaleError.
+func (a *Api) FindGroupSlots(ctx context.Context, groups []Group, from, to time.Time, opts GroupSlotOptions) ([]SlotCandidate, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period: %v - %v", from, to)
+	}
+	bells, err := a.bellSchedule()
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the timetables of the groups and the teachers.
+	var objs []Object
+	for _, group := range groups {
+		objs = append(objs, group)
+	}
+	for _, teacher := range opts.Teachers {
+		objs = append(objs, teacher)
+	}
+	tts, err := a.getTimetables(ctx, objs, from, to, opts.Concurrency)
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	firstStale := staleError(err)
+	groupTTs, teacherTTs := tts[:len(groups)], tts[len(groups):]
+
+	// Fetch the timetables of the rooms.
+	var rooms []Room
+	var roomTTs []timetable
+	if opts.Rooms != nil {
+		all, err := a.GetRoomsContext(ctx)
+		if err != nil && !IsStale(err) {
+			return nil, err
+		}
+		if firstStale == nil {
+			firstStale = staleError(err)
+		}
+		var objs []Object
+		for _, room := range all {
+			if opts.Rooms.match(room) {
+				rooms = append(rooms, room)
+				objs = append(objs, room)
+			}
+		}
+		roomTTs, err = a.getTimetables(ctx, objs, from, to, opts.Concurrency)
+		if err != nil && !IsStale(err) {
+			return nil, err
+		}
+		if firstStale == nil {
+			firstStale = staleError(err)
+		}
+	}
+
+	// The slots come from the bell schedule, also the ones nobody has a lesson in.
+	free := a.freeWindows(nil, bells, from, to, WindowOptions{AlignToSlots: true})
+
+	var candidates []SlotCandidate
+	for _, w := range free {
+		// The windows join the consecutive slots, every slot is a candidate.
+		for _, n := range w.Slots {
+			slot, _ := bells.Slot(n)
+			c := SlotCandidate{Slot: slot}
+			c.Start, c.End = slot.On(w.Start)
+			if !c.check(groupTTs, teacherTTs, bells, opts.AllowSubgroupConflicts) {
+				continue
+			}
+			if opts.Rooms != nil {
+				for i, room := range rooms {
+					if !roomTTs[i].busy(c.Start, c.End) {
+						c.Rooms = append(c.Rooms, room)
+					}
+				}
+				if len(c.Rooms) == 0 {
+					continue
+				}
+			}
+			candidates = append(candidates, c)
+		}
+	}
+
+	// Rank the slots.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ci, cj := candidates[i], candidates[j]
+		if len(ci.SubgroupConflicts) != len(cj.SubgroupConflicts) {
+			return len(ci.SubgroupConflicts) < len(cj.SubgroupConflicts)
+		}
+		if ci.Adjacent != cj.Adjacent {
+			return ci.Adjacent > cj.Adjacent
+		}
+		return ci.Start.Before(cj.Start)
+	})
+	return candidates, firstStale
+}
+
+// check reports whether the groups and the teachers can meet in the slot and fills in the conflicts and the adjacency.
+func (c *SlotCandidate) check(groupTTs, teacherTTs []timetable, bells *BellSchedule, allowSubgroups bool) bool {
+	for _, tt := range teacherTTs {
+		if tt.busy(c.Start, c.End) {
+			return false
+		}
+	}
+
+	// The slots right before and after this one.
+	var before, after interval
+	for i, slot := range bells.Slots {
+		if slot.Number != c.Slot.Number {
+			continue
+		}
+		if i > 0 {
+			before.start, before.end = bells.Slots[i-1].On(c.Start)
+		}
+		if i+1 < len(bells.Slots) {
+			after.start, after.end = bells.Slots[i+1].On(c.Start)
+		}
+	}
+
+	for _, tt := range groupTTs {
+		for _, res := range tt.Reservations {
+			if overlaps(res.StartTime, res.EndTime, c.Start, c.End) {
+				return false
+			}
+		}
+		adjacent := false
+		// The subgroups of the group busy in the slot.
+		subgroups := make(map[int]bool)
+		for _, lesson := range tt.Lessons {
+			if overlaps(lesson.StartTime, lesson.EndTime, c.Start, c.End) {
+				// A lesson of a subgroup keeps only a part of the group busy,
+				// lessons of two subgroups keep all of it busy.
+				if lesson.SubgroupNumber == 0 || !allowSubgroups {
+					return false
+				}
+				subgroups[lesson.SubgroupNumber] = true
+				if len(subgroups) > 1 {
+					return false
+				}
+				c.SubgroupConflicts = append(c.SubgroupConflicts, lesson)
+				continue
+			}
+			if !before.start.IsZero() && overlaps(lesson.StartTime, lesson.EndTime, before.start, before.end) ||
+				!after.start.IsZero() && overlaps(lesson.StartTime, lesson.EndTime, after.start, after.end) {
+				adjacent = true
+			}
+		}
+		if adjacent {
+			c.Adjacent++
+		}
+	}
+	return true
+}
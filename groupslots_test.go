@@ -0,0 +1,137 @@
+package psrozklad
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindGroupSlots(t *testing.T) {
+	lessonsOf := func(items string) string {
+		return `{"psrozklad_export": {"roz_items": [` + items + `], "code": "0"}}`
+	}
+	client := &lockedHttpClient{client: &routeHttpClient{routes: []route{
+		{"req_type=obj_list&req_mode=room", `{"psrozklad_export": {"blocks": [{"name": "№1", "objects": [{"name": "320/№1", "ID": "36"}]}], "code": "0"}}`},
+		{"OBJ_ID=11&", lessonsOf(`
+			{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20"},
+			{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "3", "lesson_time": "12:20-13:40", "group": "(підгр. 2)"}`)},
+		{"OBJ_ID=12&", lessonsOf(`
+			{"object": "22Бд-СОмат", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50"}`)},
+		{"OBJ_ID=420&", lessonsOf(`
+			{"object": "Горобець Сергій Миколайович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "14:00-15:20"}`)},
+		{"OBJ_ID=36&", lessonsOf(`
+			{"object": "320/№1", "date": "16.10.2023", "lesson_number": "3", "lesson_time": "12:20-13:40"}`)},
+	}}}
+	bells, err := NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		Slot{Number: 3, Start: clock(12, 20), End: clock(13, 40)},
+		Slot{Number: 4, Start: clock(14, 0), End: clock(15, 20)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api := newTestApi(t, WithHTTPClient(client), WithBellSchedule(bells))
+	groups := []Group{{Name: "21Бд-СОмат", Id: 11}, {Name: "22Бд-СОмат", Id: 12}}
+	from := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+	to := from.AddDate(0, 0, 1)
+
+	testCases := []struct {
+		desc      string
+		opts      GroupSlotOptions
+		slots     []int
+		conflicts []int
+		adjacent  []int
+		rooms     []int
+	}{
+		{"everybody free", GroupSlotOptions{}, []int{4}, []int{0}, []int{1}, []int{0}},
+		{"subgroup conflicts", GroupSlotOptions{AllowSubgroupConflicts: true}, []int{4, 3}, []int{0, 1}, []int{1, 1}, []int{0, 0}},
+		{"busy teacher", GroupSlotOptions{AllowSubgroupConflicts: true, Teachers: []Teacher{{Id: 420}}}, []int{3}, []int{1}, []int{1}, []int{0}},
+		{"free room", GroupSlotOptions{AllowSubgroupConflicts: true, Rooms: &RoomFilter{}}, []int{4}, []int{0}, []int{1}, []int{1}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, err := api.FindGroupSlots(context.Background(), groups, from, to, tC.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tC.slots) {
+				t.Fatalf("want slots %v, got %+v", tC.slots, got)
+			}
+			for i, c := range got {
+				if c.Slot.Number != tC.slots[i] || len(c.SubgroupConflicts) != tC.conflicts[i] || c.Adjacent != tC.adjacent[i] || len(c.Rooms) != tC.rooms[i] {
+					t.Errorf("candidate %d: unexpected %+v", i, c)
+				}
+				if start, _ := c.Slot.On(from); !c.Start.Equal(start) {
+					t.Errorf("candidate %d: unexpected start %v", i, c.Start)
+				}
+			}
+		})
+	}
+}
+
+func TestFindGroupSlotsEmptySlot(t *testing.T) {
+	lessonsOf := func(object string) string {
+		return `{"psrozklad_export": {"roz_items": [{"object": "` + object + `", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50"}], "code": "0"}}`
+	}
+	client := &lockedHttpClient{client: &routeHttpClient{routes: []route{
+		{"OBJ_ID=11&", lessonsOf("21Бд-СОмат")},
+		{"OBJ_ID=12&", lessonsOf("22Бд-СОмат")},
+	}}}
+	groups := []Group{{Name: "21Бд-СОмат", Id: 11}, {Name: "22Бд-СОмат", Id: 12}}
+	from := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+	to := from.AddDate(0, 0, 1)
+
+	api := newTestApi(t, WithHTTPClient(client))
+	if _, err := api.FindGroupSlots(context.Background(), groups, from, to, GroupSlotOptions{}); !errors.Is(err, ErrNoBellSchedule) {
+		t.Errorf("want ErrNoBellSchedule, got: %v", err)
+	}
+
+	// Nobody has a lesson in slots 1, 3 and 4, the first one right before the common lesson is the best.
+	api.Bells, _ = NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		Slot{Number: 3, Start: clock(12, 20), End: clock(13, 40)},
+		Slot{Number: 4, Start: clock(14, 0), End: clock(15, 20)},
+	)
+	got, err := api.FindGroupSlots(context.Background(), groups, from, to, GroupSlotOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var slots []int
+	for _, c := range got {
+		slots = append(slots, c.Slot.Number)
+	}
+	if want := []int{1, 3, 4}; !reflect.DeepEqual(slots, want) || got[0].Adjacent != 2 || got[2].Adjacent != 0 {
+		t.Errorf("want slots %v, got %+v", want, got)
+	}
+}
+
+func TestFindGroupSlotsBothSubgroups(t *testing.T) {
+	client := &lockedHttpClient{client: &routeHttpClient{routes: []route{
+		{"OBJ_ID=11&", `{"psrozklad_export": {"roz_items": [
+			{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "group": "(підгр. 1)"},
+			{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "group": "(підгр. 2)"},
+			{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50", "group": "(підгр. 1)"}], "code": "0"}}`},
+	}}}
+	bells, err := NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api := newTestApi(t, WithHTTPClient(client), WithBellSchedule(bells))
+	from := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+
+	// Both subgroups are busy in slot 1, so the whole group is.
+	got, err := api.FindGroupSlots(context.Background(), []Group{{Name: "21Бд-СОмат", Id: 11}}, from, from.AddDate(0, 0, 1), GroupSlotOptions{AllowSubgroupConflicts: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Slot.Number != 2 || len(got[0].SubgroupConflicts) != 1 {
+		t.Errorf("unexpected slots: %+v", got)
+	}
+}
@@ -0,0 +1,43 @@
+package psrozklad
+
+import (
+	"strings"
+)
+
+// Half tells which half of the pair a lesson takes.
+type Half int
+
+const (
+	// HalfFull is a lesson that takes the whole pair.
+	HalfFull Half = iota
+	// HalfFirst is a lesson in the first half of the pair.
+	HalfFirst
+	// HalfSecond is a lesson in the second half of the pair.
+	HalfSecond
+)
+
+func (h Half) String() string {
+	switch h {
+	case HalfFirst:
+		return "first"
+	case HalfSecond:
+		return "second"
+	default:
+		return "full"
+	}
+}
+
+// parseHalf parses the half field of the API, e.g. "1", "2" or "1-а половина".
+func parseHalf(s string) Half {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case s == "", s == "0":
+		return HalfFull
+	case strings.HasPrefix(s, "1"), strings.HasPrefix(s, "перш"):
+		return HalfFirst
+	case strings.HasPrefix(s, "2"), strings.HasPrefix(s, "друг"):
+		return HalfSecond
+	default:
+		return HalfFull
+	}
+}
@@ -0,0 +1,64 @@
+package psrozklad
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHalf(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want Half
+	}{
+		{"", HalfFull},
+		{"0", HalfFull},
+		{"1", HalfFirst},
+		{" 2 ", HalfSecond},
+		{"1-а половина", HalfFirst},
+		{"Друга половина", HalfSecond},
+		{"перша", HalfFirst},
+		{"щось", HalfFull},
+	}
+	for _, tC := range testCases {
+		if got := parseHalf(tC.in); got != tC.want {
+			t.Errorf("parseHalf(%q) = %v, want %v", tC.in, got, tC.want)
+		}
+	}
+}
+
+func TestLessonExtraFields(t *testing.T) {
+	body := `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20",
+		"teacher": "Горобець С.М.", "comment": "Перенесено", "lesson_name": "I", "half": "2",
+		"teachers_add": "Яценко О.С.; Новенький Н.Н.", "future_field": "x"}], "code": "0"}}`
+	api := newTestApi(t, WithHTTPClient(&MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}))
+	teacher := Teacher{ShortName: "Яценко О.С.", Id: 486}
+	api.Directory.SetTeachers([]Teacher{teacher})
+
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+	lessons, err := api.GetLessons(Group{Name: "21Бд-СОмат", Id: 11}, day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lesson := lessons[0]
+	if lesson.Comment != "Перенесено" || lesson.LessonName != "I" || lesson.Half != HalfSecond {
+		t.Errorf("unexpected fields: %q, %q, %v", lesson.Comment, lesson.LessonName, lesson.Half)
+	}
+	if want := []Teacher{teacher}; !reflect.DeepEqual(lesson.AdditionalTeachers, want) {
+		t.Errorf("unexpected additional teachers: %+v", lesson.AdditionalTeachers)
+	}
+	wantRefs := []Ref{{Raw: "Яценко О.С.", Resolved: true}, {Raw: "Новенький Н.Н."}}
+	if !reflect.DeepEqual(lesson.AdditionalTeacherRefs, wantRefs) {
+		t.Errorf("unexpected additional teacher references: %+v", lesson.AdditionalTeacherRefs)
+	}
+
+	// Fields unknown to the package are kept in the raw item.
+	var raw map[string]string
+	if err := json.Unmarshal(lesson.Raw, &raw); err != nil || raw["future_field"] != "x" {
+		t.Errorf("unexpected raw item %s: %v", lesson.Raw, err)
+	}
+}
@@ -1,9 +1,10 @@
 package psrozklad
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"strconv"
 	"time"
 )
@@ -14,25 +15,42 @@ type Object interface {
 }
 
 type Lesson struct {
-	Title          string
-	Teacher        Teacher
-	Type           string
-	Day            string
-	Number         int
-	Room           Room
-	GroupsType     string
-	Groups         []Group
-	SubGroup       string
+	Title      string
+	Teacher    Teacher
+	Type       string
+	LessonType LessonType
+	Day        string
+	Number     int
+	Room       Room
+	GroupsType string
+	Groups     []Group
+	SubGroup   string
+	// SubgroupNumber is the number of the subgroup the lesson is for, 0 if it is common for the whole group.
+	SubgroupNumber int
+	Assignment     GroupAssignment
+	// TeacherRef, RoomRef and GroupRefs keep the text of the API for the Teacher, the Room and the Groups.
+	TeacherRef     Ref
+	RoomRef        Ref
+	GroupRefs      []Ref
 	StartTime      time.Time
 	EndTime        time.Time
 	Online         bool
 	URL            string
 	CommentForLink string
-	Replacement    struct {
-		Title   string
-		Teacher Teacher
-		Type    string
-	}
+	Replacement    Replacement
+	// Comment is the comment of the lesson.
+	Comment string
+	// LessonName is the name of the lesson slot, usually the same as Number.
+	LessonName string
+	Half       Half
+	// Teachers are the resolved teachers of the lesson: the Teacher and the AdditionalTeachers.
+	// The unresolved ones are only known by TeacherRef and AdditionalTeacherRefs.
+	Teachers []Teacher
+	// AdditionalTeachers are the resolved co-teachers from teachers_add, AdditionalTeacherRefs keep the text of all of them.
+	AdditionalTeachers    []Teacher
+	AdditionalTeacherRefs []Ref
+	// Raw is the item as the API returned it, for fields this package doesn't know about.
+	Raw json.RawMessage
 }
 
 type lessonExport struct {
@@ -49,10 +67,99 @@ type lessonExport struct {
 	Online       string `json:"online"`
 	Link         string `json:"link"`
 	Comment4link string `json:"comment4link"`
+	Comment      string `json:"comment"`
+	LessonName   string `json:"lesson_name"`
+	Half         string `json:"half"`
+	TeachersAdd  string `json:"teachers_add"`
+	Reservation  string `json:"reservation"`
+
+	// raw is the item as the API returned it.
+	raw json.RawMessage
+}
+
+// UnmarshalJSON decodes the item and keeps a copy of it.
+func (l *lessonExport) UnmarshalJSON(data []byte) error {
+	// plain has the fields but not the methods of lessonExport, so decoding it doesn't recurse.
+	type plain lessonExport
+	err := json.Unmarshal(data, (*plain)(l))
+	if err != nil {
+		return err
+	}
+	l.raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 // GetLessons gets the lessons from the timetable export for the given object and time period.
+// The reservations of rooms are not lessons, GetTimetable returns them too.
 func (a *Api) GetLessons(obj Object, start, end time.Time) ([]Lesson, error) {
+	return a.GetLessonsContext(context.Background(), obj, start, end)
+}
+
+// GetLessonsContext is like GetLessons but uses ctx for the http request.
+func (a *Api) GetLessonsContext(ctx context.Context, obj Object, start, end time.Time) ([]Lesson, error) {
+	tt, err := a.getTimetable(ctx, obj, start, end)
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+	return tt.Lessons, err
+}
+
+// timetable holds the lessons and the reservations of an object, it is what the caches store.
+type timetable struct {
+	Lessons      []Lesson
+	Reservations []Reservation
+}
+
+// copy returns a deep copy of the timetable, so the caller can change it without changing the cache.
+func (tt timetable) copy() timetable {
+	c := timetable{
+		Lessons:      make([]Lesson, len(tt.Lessons)),
+		Reservations: make([]Reservation, len(tt.Reservations)),
+	}
+	for i, lesson := range tt.Lessons {
+		c.Lessons[i] = lesson.clone()
+	}
+	for i, res := range tt.Reservations {
+		res.Raw = append(json.RawMessage(nil), res.Raw...)
+		c.Reservations[i] = res
+	}
+	return c
+}
+
+// in returns the timetable with its times in loc. The times decoded from JSON have a fixed offset instead of the zone.
+func (tt timetable) in(loc *time.Location) timetable {
+	for i := range tt.Lessons {
+		tt.Lessons[i].StartTime = tt.Lessons[i].StartTime.In(loc)
+		tt.Lessons[i].EndTime = tt.Lessons[i].EndTime.In(loc)
+	}
+	for i := range tt.Reservations {
+		tt.Reservations[i].StartTime = tt.Reservations[i].StartTime.In(loc)
+		tt.Reservations[i].EndTime = tt.Reservations[i].EndTime.In(loc)
+	}
+	return tt
+}
+
+// clone returns a copy of the lesson that shares no slices with it.
+func (l Lesson) clone() Lesson {
+	l.Groups = append([]Group(nil), l.Groups...)
+	l.Assignment.Names = append([]string(nil), l.Assignment.Names...)
+	l.Assignment.Groups = append([]Group(nil), l.Assignment.Groups...)
+	l.GroupRefs = append([]Ref(nil), l.GroupRefs...)
+	l.Teachers = append([]Teacher(nil), l.Teachers...)
+	l.AdditionalTeachers = append([]Teacher(nil), l.AdditionalTeachers...)
+	l.AdditionalTeacherRefs = append([]Ref(nil), l.AdditionalTeacherRefs...)
+	l.Raw = append(json.RawMessage(nil), l.Raw...)
+	return l
+}
+
+// getTimetable gets the lessons and the reservations from the timetable export for the given object and time period.
+func (a *Api) getTimetable(ctx context.Context, obj Object, start, end time.Time) (timetable, error) {
+	return a.loadTimetable(ctx, obj, start, end, true)
+}
+
+// loadTimetable is getTimetable that, unless readCache is set, asks the API instead of the caches.
+// The fetched timetable is still stored in the caches, and the disk cache is still used if the API is unreachable.
+func (a *Api) loadTimetable(ctx context.Context, obj Object, start, end time.Time, readCache bool) (timetable, error) {
 
 	// Create a timetable export struct to decode the JSON response into.
 	type timetableExport struct {
@@ -65,48 +172,119 @@ func (a *Api) GetLessons(obj Object, start, end time.Time) ([]Lesson, error) {
 		Timetable timetableExport `json:"psrozklad_export"`
 	}
 
+	// The object is needed to build the request.
+	if obj == nil {
+		return timetable{}, fmt.Errorf("%w: nil object", ErrUnknownObject)
+	}
+
+	// The dates of the request are the dates in the timezone of the university.
+	start, end = start.In(a.location()), end.In(a.location())
+
+	// Return the cached timetable if there is one.
+	key := lessonsKey(obj, start, end)
+	if readCache {
+		if cached, ok := a.Cache.get(key); ok {
+			return cached.(timetable).copy(), nil
+		}
+	}
+
 	// Build the URL for the timetable export request.
 	url := a.BaseUri
-	startY, startM, startD := start.Date()
-	endY, endM, endD := end.Date()
-	url += "&begin_date=" + strconv.Itoa(startD) + "." + strconv.Itoa(int(startM)) + "." + strconv.Itoa(startY)
-	url += "&end_date=" + strconv.Itoa(endD) + "." + strconv.Itoa(int(endM)) + "." + strconv.Itoa(endY)
+	url += "&begin_date=" + formatDate(start)
+	url += "&end_date=" + formatDate(end)
 	url += "&OBJ_ID=" + strconv.Itoa(obj.ID()) + "&ros_text=separated"
 	url += "&req_mode=" + obj.type_obj()
 
-	// Create a new HTTP request for the timetable export.
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	// Serve the fresh parsed timetable from the disk cache.
+	// With the refresh policy, a timetable with missing objects is fetched again instead.
+	diskKey := "lessons:" + url
+	if readCache {
+		if body, ok := a.DiskCache.fresh(diskKey); ok {
+			var tt timetable
+			if json.Unmarshal(body, &tt) == nil && (!a.MissPolicy.Refresh || resolvedLessons(tt.Lessons)) {
+				tt = tt.in(a.location())
+				a.Cache.set(key, tt.copy())
+				return tt, nil
+			}
+		}
 	}
 
-	// Make the request to the timetable export endpoint.
-	resp, err := a.HttpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get lessons: %v", err)
+	// Make the request to the timetable export endpoint and decode the JSON response into an export struct.
+	var exp export
+	var err error
+	if readCache {
+		err = a.getJSON(ctx, url, &exp)
+	} else {
+		err = a.fetchJSON(ctx, url, &exp)
+	}
+	stale := staleError(err)
+	if err != nil && stale == nil {
+		return timetable{}, fmt.Errorf("failed to get lessons: %w", err)
 	}
-	defer resp.Body.Close()
 
-	// Decode the JSON response into an export struct.
-	var exp export
-	err = json.NewDecoder(resp.Body).Decode(&exp)
+	// If the API is unreachable, prefer the timetable parsed when the directory was up to date.
+	if stale != nil {
+		if body, fetchedAt, ok := a.DiskCache.stale(diskKey); ok {
+			var tt timetable
+			if json.Unmarshal(body, &tt) == nil {
+				return tt.in(a.location()), &StaleError{FetchedAt: fetchedAt, Err: errors.Unwrap(stale)}
+			}
+		}
+	}
+
+	// Convert the lesson export items to lessons and reservations.
+	tt, err := a.convertRozItems(exp.Timetable.RozItems, obj.type_obj())
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode lessons json: %v", err)
+		return timetable{}, err
 	}
 
+	// Refresh the directory and convert again if some objects are missing.
+	if a.refreshMissing(ctx, tt.Lessons) {
+		tt, err = a.convertRozItems(exp.Timetable.RozItems, obj.type_obj())
+		if err != nil {
+			return timetable{}, err
+		}
+	}
+
+	// Store the timetable in the caches, unless it is stale.
+	if stale == nil {
+		a.Cache.set(key, tt.copy())
+		if body, err := json.Marshal(tt); err == nil {
+			a.DiskCache.store(diskKey, body)
+		}
+		return tt, nil
+	}
+
+	// Return the stale timetable.
+	return tt, stale
+}
+
+// convertRozItems converts the lesson export items to lessons and reservations, resolving them in the directory of the Api.
+func (a *Api) convertRozItems(items []lessonExport, t string) (timetable, error) {
 	// Create a slice to store the lessons.
 	var lessons, lessons_temp []Lesson
+	var reservations []Reservation
 
 	// Keep track of the current lesson number.
 	var num int
 
 	// Iterate over the lesson export items in the timetable export.
-	for _, lesson := range exp.Timetable.RozItems {
+	for _, lesson := range items {
+
+		// Reservations of rooms are not lessons.
+		if lesson.isReservation() {
+			res, err := convertLessonExportToReservation(a.dir(), a.location(), lesson, t)
+			if err != nil {
+				return timetable{}, fmt.Errorf("failed to convert lessonExport to Reservation: %w", err)
+			}
+			reservations = append(reservations, res)
+			continue
+		}
 
 		// Convert the lesson export item to a Lesson struct.
-		less_new, err := convertLessonExportToLesson(lesson, obj.type_obj())
+		less_new, err := convertLessonExportToLesson(a.dir(), a.location(), a.logf, lesson, t)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert lessonExport to Lesson: %v", err)
+			return timetable{}, fmt.Errorf("failed to convert lessonExport to Lesson: %w", err)
 		}
 
 		// If the current lesson number is 0, add the lesson to the lessons_temp slice.
@@ -120,7 +298,7 @@ func (a *Api) GetLessons(obj Object, start, end time.Time) ([]Lesson, error) {
 			// If the current lesson number is different from the previous lesson number, convert the lessons_temp slice to a slice of Lesson structs and add it to the lessons slice. Then, clear the lessons_temp slice and add the current lesson to it.
 			lessons = append(lessons, convertLessons(lessons_temp)...)
 			lessons_temp = nil
-			num = 0
+			num = less_new.Number
 			lessons_temp = append(lessons_temp, less_new)
 		}
 	}
@@ -128,6 +306,5 @@ func (a *Api) GetLessons(obj Object, start, end time.Time) ([]Lesson, error) {
 	// Convert the lessons_temp slice to a slice of Lesson structs and add it to the lessons slice.
 	lessons = append(lessons, convertLessons(lessons_temp)...)
 
-	// Return the lessons slice.
-	return lessons, nil
+	return timetable{Lessons: lessons, Reservations: reservations}, nil
 }
@@ -2,9 +2,11 @@ package psrozklad
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -113,9 +115,20 @@ func TestGetLessons(t *testing.T) {
 						Id:          420,
 						Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
 					},
-					Type:   "Л",
-					Day:    "16.10.2023",
-					Number: 1,
+					Teachers: []Teacher{
+						{
+							ShortName:   "Горобець С.М.",
+							P:           "Горобець",
+							I:           "C",
+							B:           "Миколайович",
+							Id:          420,
+							Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
+						},
+					},
+					Type:       "Л",
+					LessonType: LessonLecture,
+					Day:        "16.10.2023",
+					Number:     1,
 					Room: Room{
 						Block:    "№1",
 						Name:     "320",
@@ -135,12 +148,37 @@ func TestGetLessons(t *testing.T) {
 							Departament: "Фізико-математичний факультет",
 						},
 					},
-					SubGroup:       "21Бд-СОмат, 22Бд-СОмат",
-					StartTime:      time.Date(2023, time.October, 16, 9, 0, 0, 0, time.Local),
-					EndTime:        time.Date(2023, time.October, 16, 10, 20, 0, 0, time.Local),
+					SubGroup: "21Бд-СОмат, 22Бд-СОмат",
+					Assignment: GroupAssignment{
+						Kind:  GroupStream,
+						Names: []string{"21Бд-СОмат", "22Бд-СОмат"},
+						Groups: []Group{
+							{
+								Name:        "21Бд-СОмат",
+								Id:          11,
+								Departament: "Фізико-математичний факультет",
+							},
+							{
+								Name:        "22Бд-СОмат",
+								Id:          12,
+								Departament: "Фізико-математичний факультет",
+							},
+						},
+						Label: "21Бд-СОмат, 22Бд-СОмат",
+					},
+					TeacherRef: Ref{Raw: "Горобець С.М.", Resolved: true},
+					RoomRef:    Ref{Raw: "320/№1", Resolved: true},
+					GroupRefs: []Ref{
+						{Raw: "21Бд-СОмат", Resolved: true},
+						{Raw: "22Бд-СОмат", Resolved: true},
+					},
+					StartTime:      time.Date(2023, time.October, 16, 9, 0, 0, 0, Kyiv),
+					EndTime:        time.Date(2023, time.October, 16, 10, 20, 0, 0, Kyiv),
 					Online:         true,
 					URL:            "https://us05web.zoom.us/j/9799712364?pwd=f5hSQnbCbnvU6ACFWEyQT6wMBBzk0v.1",
 					CommentForLink: "Ідентифікатор: 979 971 2364; Пароль: 2023",
+					Comment:        "0",
+					LessonName:     "1",
 				},
 			},
 		},
@@ -267,9 +305,20 @@ func TestGetLessons(t *testing.T) {
 						Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
 						Id:          486,
 					},
-					Type:   "Лаб",
-					Day:    "16.10.2023",
-					Number: 4,
+					Teachers: []Teacher{
+						{
+							ShortName:   "Яценко О.С.",
+							P:           "Яценко",
+							I:           "Олександр",
+							B:           "Сергійович",
+							Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
+							Id:          486,
+						},
+					},
+					Type:       "Лаб",
+					LessonType: LessonLab,
+					Day:        "16.10.2023",
+					Number:     4,
 					Room: Room{
 						Block:    "№1",
 						Name:     "320",
@@ -284,9 +333,30 @@ func TestGetLessons(t *testing.T) {
 							Departament: "Фізико-математичний факультет",
 						},
 					},
-					SubGroup:  "(підгр. 1)",
-					StartTime: time.Date(2023, time.October, 16, 13, 40, 0, 0, time.Local),
-					EndTime:   time.Date(2023, time.October, 16, 15, 0, 0, 0, time.Local),
+					SubGroup:       "(підгр. 1)",
+					SubgroupNumber: 1,
+					Assignment: GroupAssignment{
+						Kind:  GroupSubgroup,
+						Names: []string{"22Бд-СОмат"},
+						Groups: []Group{
+							{
+								Name:        "22Бд-СОмат",
+								Id:          12,
+								Departament: "Фізико-математичний факультет",
+							},
+						},
+						Subgroup: 1,
+						Label:    "(підгр. 1)",
+					},
+					TeacherRef: Ref{Raw: "Яценко О.С.", Resolved: true},
+					RoomRef:    Ref{Raw: "320/№1", Resolved: true},
+					GroupRefs: []Ref{
+						{Raw: "22Бд-СОмат", Resolved: true},
+					},
+					StartTime:  time.Date(2023, time.October, 16, 13, 40, 0, 0, Kyiv),
+					EndTime:    time.Date(2023, time.October, 16, 15, 0, 0, 0, Kyiv),
+					Comment:    "0",
+					LessonName: "4",
 				},
 				{
 					Title: "Комп‘ютерні мережі",
@@ -298,9 +368,20 @@ func TestGetLessons(t *testing.T) {
 						Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
 						Id:          420,
 					},
-					Type:   "Лаб",
-					Day:    "16.10.2023",
-					Number: 4,
+					Teachers: []Teacher{
+						{
+							ShortName:   "Кривонос О.М.",
+							P:           "Кривонос",
+							I:           "Олександр",
+							B:           "Миколайович",
+							Departament: "Кафедра комп‘ютерних наук та інформаційних технологій",
+							Id:          420,
+						},
+					},
+					Type:       "Лаб",
+					LessonType: LessonLab,
+					Day:        "16.10.2023",
+					Number:     4,
 					Room: Room{
 						Block:    "№1",
 						Name:     "320",
@@ -320,9 +401,34 @@ func TestGetLessons(t *testing.T) {
 							Departament: "Фізико-математичний факультет",
 						},
 					},
-					SubGroup:  "21Бд-СОмат, 22Бд-СОмат",
-					StartTime: time.Date(2023, time.October, 16, 13, 40, 0, 0, time.Local),
-					EndTime:   time.Date(2023, time.October, 16, 15, 0, 0, 0, time.Local),
+					SubGroup: "21Бд-СОмат, 22Бд-СОмат",
+					Assignment: GroupAssignment{
+						Kind:  GroupCombined,
+						Names: []string{"21Бд-СОмат", "22Бд-СОмат"},
+						Groups: []Group{
+							{
+								Name:        "21Бд-СОмат",
+								Id:          11,
+								Departament: "Фізико-математичний факультет",
+							},
+							{
+								Name:        "22Бд-СОмат",
+								Id:          12,
+								Departament: "Фізико-математичний факультет",
+							},
+						},
+						Label: "21Бд-СОмат, 22Бд-СОмат",
+					},
+					TeacherRef: Ref{Raw: "Кривонос О.М.", Resolved: true},
+					RoomRef:    Ref{Raw: "320/№1", Resolved: true},
+					GroupRefs: []Ref{
+						{Raw: "21Бд-СОмат", Resolved: true},
+						{Raw: "22Бд-СОмат", Resolved: true},
+					},
+					StartTime:  time.Date(2023, time.October, 16, 13, 40, 0, 0, Kyiv),
+					EndTime:    time.Date(2023, time.October, 16, 15, 0, 0, 0, Kyiv),
+					Comment:    "0",
+					LessonName: "4",
 				},
 			},
 		},
@@ -359,7 +465,9 @@ func TestGetLessons(t *testing.T) {
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
+			dir := NewDirectory()
 			api := Api{
+				Directory: dir,
 				HttpClient: &MockHttpClient{
 					http.Response{
 						Body: io.NopCloser(bytes.NewBufferString(tC.json)),
@@ -367,6 +475,7 @@ func TestGetLessons(t *testing.T) {
 				},
 			}
 			apiRooms := Api{
+				Directory: dir,
 				HttpClient: &MockHttpClient{
 					http.Response{
 						Body: io.NopCloser(bytes.NewBufferString(tC.roomJson)),
@@ -375,6 +484,7 @@ func TestGetLessons(t *testing.T) {
 			}
 			apiRooms.InitRooms()
 			apiTeachers := Api{
+				Directory: dir,
 				HttpClient: &MockHttpClient{
 					http.Response{
 						Body: io.NopCloser(bytes.NewBufferString(tC.teacherJson)),
@@ -383,6 +493,7 @@ func TestGetLessons(t *testing.T) {
 			}
 			apiTeachers.InitTeachers()
 			apiGroups := Api{
+				Directory: dir,
 				HttpClient: &MockHttpClient{
 					http.Response{
 						Body: io.NopCloser(bytes.NewBufferString(tC.groupeJson)),
@@ -395,6 +506,13 @@ func TestGetLessons(t *testing.T) {
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
+			// The raw items are checked separately, they are too long to compare.
+			for i := range got {
+				if !json.Valid(got[i].Raw) {
+					t.Errorf("lesson %d: invalid raw item %q", i, got[i].Raw)
+				}
+				got[i].Raw = nil
+			}
 			if !reflect.DeepEqual(got, tC.want) {
 				t.Errorf("want: \n%v\ngot: \n%v", tC.want, got)
 			}
@@ -402,3 +520,65 @@ func TestGetLessons(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLessonsTeacher(t *testing.T) {
+	api := newTestApi(t)
+	api.Directory.SetTeachers([]Teacher{{ShortName: "Яценко О.С.", P: "Яценко", I: "Олександр", B: "Сергійович", Id: 486}})
+	api.HttpClient = &MockHttpClient{resp: http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(`{"psrozklad_export": {"roz_items": [
+			{"object": "Яценко Олександр Сергійович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "13:40-15:00", "group": "22Бд-СОмат", "title": "Комп‘ютерні мережі", "type": "Лаб"},
+			{"object": "Яценко", "date": "16.10.2023", "lesson_number": "5", "lesson_time": "15:20-16:40", "group": "22Бд-СОмат"}
+		], "code": "0"}}`)),
+	}}
+
+	// A name that can't be parsed doesn't fail the timetable, the teacher of the lesson stays unresolved.
+	logger := &bufLogger{}
+	api.Logger = logger
+	lessons, err := api.GetLessons(Teacher{Id: 486}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lessons) != 2 || lessons[0].Teacher.Id != 486 || lessons[1].TeacherRef != (Ref{Raw: "Яценко"}) || len(lessons[1].Teachers) != 0 {
+		t.Errorf("unexpected lessons: %+v", lessons)
+	}
+	if !strings.Contains(logger.String(), `"Яценко"`) {
+		t.Errorf("the name was not logged: %q", logger.String())
+	}
+	api.Logger = nil
+
+	api.HttpClient = &MockHttpClient{resp: http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(`{"psrozklad_export": {"roz_items": [
+			{"object": "Яценко Олександр Сергійович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "13:40-15:00", "group": "22Бд-СОмат", "title": "Комп‘ютерні мережі", "type": "Лаб"}
+		], "code": "0"}}`)),
+	}}
+	got, err := api.GetLessons(Teacher{Id: 486}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Teacher.Id != 486 {
+		t.Errorf("unexpected lessons: %v", got)
+	}
+}
+
+func TestGetLessonsConsecutiveSlots(t *testing.T) {
+	api := newTestApi(t)
+	api.HttpClient = &MockHttpClient{resp: http.Response{
+		Body: io.NopCloser(bytes.NewBufferString(`{"psrozklad_export": {"roz_items": [
+			{"object": "22Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "title": "Графіка"},
+			{"object": "22Бд-СОмат", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50", "title": "Мережі"},
+			{"object": "22Бд-СОмат", "date": "16.10.2023", "lesson_number": "3", "lesson_time": "12:20-13:40", "group": "(підгр. 1)", "title": "Бази даних"}
+		], "code": "0"}}`)),
+	}}
+
+	got, err := api.GetLessons(Group{Name: "22Бд-СОмат", Id: 12}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("unexpected lessons: %v", got)
+	}
+	// The lessons of slot 2 are not in the same slot as the subgroup lesson of slot 3.
+	if got[1].GroupsType == "підгр" || got[2].GroupsType != "підгр" {
+		t.Errorf("unexpected groups types: %q, %q", got[1].GroupsType, got[2].GroupsType)
+	}
+}
@@ -0,0 +1,104 @@
+package psrozklad
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// LessonType is the type of a lesson, parsed from abbreviations like "Л", "Пр" or "Лаб".
+type LessonType int
+
+const (
+	LessonUnknown LessonType = iota
+	LessonLecture
+	LessonPractice
+	LessonLab
+	LessonSeminar
+	LessonConsultation
+	LessonExam
+	LessonCredit
+)
+
+// lessonTypeNames holds the abbreviation, the Ukrainian and the English name of every type.
+var lessonTypeNames = [...]struct {
+	abbreviation, ukrainian, english string
+}{
+	LessonUnknown:      {"", "Невідомо", "Unknown"},
+	LessonLecture:      {"Л", "Лекція", "Lecture"},
+	LessonPractice:     {"Пр", "Практичне заняття", "Practice"},
+	LessonLab:          {"Лаб", "Лабораторна робота", "Lab"},
+	LessonSeminar:      {"Сем", "Семінар", "Seminar"},
+	LessonConsultation: {"Конс", "Консультація", "Consultation"},
+	LessonExam:         {"Екз", "Екзамен", "Exam"},
+	LessonCredit:       {"Зал", "Залік", "Credit"},
+}
+
+// lessonTypeAliases maps the lower case spellings used by the API and by people to the types.
+var lessonTypeAliases = map[string]LessonType{
+	"л": LessonLecture, "лк": LessonLecture, "лек": LessonLecture, "лекція": LessonLecture, "lecture": LessonLecture,
+	"пр": LessonPractice, "прак": LessonPractice, "практ": LessonPractice, "практичне": LessonPractice, "практичне заняття": LessonPractice,
+	"practice": LessonPractice, "practical": LessonPractice,
+	"лаб": LessonLab, "лр": LessonLab, "лабораторна": LessonLab, "лабораторна робота": LessonLab, "lab": LessonLab, "laboratory": LessonLab,
+	"сем": LessonSeminar, "семінар": LessonSeminar, "seminar": LessonSeminar,
+	"конс": LessonConsultation, "консультація": LessonConsultation, "consultation": LessonConsultation,
+	"екз": LessonExam, "екзамен": LessonExam, "іспит": LessonExam, "exam": LessonExam, "examination": LessonExam,
+	"зал": LessonCredit, "зач": LessonCredit, "залік": LessonCredit, "диф. залік": LessonCredit, "дз": LessonCredit, "credit": LessonCredit,
+}
+
+// ParseLessonType parses an abbreviation, a Ukrainian or an English name of a lesson type.
+// It returns LessonUnknown for anything else.
+func ParseLessonType(s string) LessonType {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	s = strings.TrimSuffix(strings.Trim(s, "()"), ".")
+	return lessonTypeAliases[s]
+}
+
+// valid reports whether t is one of the declared types.
+func (t LessonType) valid() bool {
+	return t >= 0 && int(t) < len(lessonTypeNames)
+}
+
+// String returns the lower case English name, e.g. "lecture".
+func (t LessonType) String() string {
+	return strings.ToLower(t.English())
+}
+
+// Abbreviation returns the abbreviation used by the API, e.g. "Л".
+func (t LessonType) Abbreviation() string {
+	if !t.valid() {
+		return ""
+	}
+	return lessonTypeNames[t].abbreviation
+}
+
+// Ukrainian returns the Ukrainian name, e.g. "Лекція".
+func (t LessonType) Ukrainian() string {
+	if !t.valid() {
+		t = LessonUnknown
+	}
+	return lessonTypeNames[t].ukrainian
+}
+
+// English returns the English name, e.g. "Lecture".
+func (t LessonType) English() string {
+	if !t.valid() {
+		t = LessonUnknown
+	}
+	return lessonTypeNames[t].english
+}
+
+// MarshalJSON encodes the type as its String.
+func (t LessonType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes any spelling accepted by ParseLessonType.
+func (t *LessonType) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+	*t = ParseLessonType(s)
+	return nil
+}
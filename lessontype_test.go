@@ -0,0 +1,74 @@
+package psrozklad
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseLessonType(t *testing.T) {
+	testCases := []struct {
+		s    string
+		want LessonType
+	}{
+		{"Л", LessonLecture},
+		{"(Лк)", LessonLecture},
+		{"Пр", LessonPractice},
+		{"практичне  заняття", LessonPractice},
+		{"Лаб", LessonLab},
+		{"Lab", LessonLab},
+		{"Сем.", LessonSeminar},
+		{"Конс", LessonConsultation},
+		{"Екз", LessonExam},
+		{"Іспит", LessonExam},
+		{"Зал", LessonCredit},
+		{"Диф. залік", LessonCredit},
+		{"", LessonUnknown},
+		{"Факультатив", LessonUnknown},
+	}
+	for _, tC := range testCases {
+		if got := ParseLessonType(tC.s); got != tC.want {
+			t.Errorf("%q: want %v, got %v", tC.s, tC.want, got)
+		}
+	}
+}
+
+func TestLessonTypeNames(t *testing.T) {
+	if got := LessonLab.Ukrainian(); got != "Лабораторна робота" {
+		t.Errorf("unexpected Ukrainian name: %q", got)
+	}
+	if got := LessonExam.English(); got != "Exam" {
+		t.Errorf("unexpected English name: %q", got)
+	}
+	if got := LessonPractice.Abbreviation(); got != "Пр" {
+		t.Errorf("unexpected abbreviation: %q", got)
+	}
+	if got := LessonType(42).String(); got != "unknown" {
+		t.Errorf("unexpected name of an invalid type: %q", got)
+	}
+	// Every type can be parsed back from its names.
+	for lt := LessonUnknown + 1; lt <= LessonCredit; lt++ {
+		for _, name := range []string{lt.Abbreviation(), lt.Ukrainian(), lt.English()} {
+			if got := ParseLessonType(name); got != lt {
+				t.Errorf("%q: want %v, got %v", name, lt, got)
+			}
+		}
+	}
+}
+
+func TestLessonTypeJSON(t *testing.T) {
+	data, err := json.Marshal(struct{ Type LessonType }{LessonSeminar})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != `{"Type":"seminar"}` {
+		t.Errorf("unexpected json: %s", data)
+	}
+
+	var got struct{ Type LessonType }
+	if err := json.Unmarshal([]byte(`{"Type":"Лаб"}`), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Type != LessonLab {
+		t.Errorf("want %v, got %v", LessonLab, got.Type)
+	}
+}
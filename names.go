@@ -0,0 +1,87 @@
+package psrozklad
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FullName is the full name of a teacher, e.g. "Горобець Сергій Миколайович".
+type FullName struct {
+	Surname    string
+	Name       string
+	Patronymic string
+}
+
+// ParseFullName parses a full name written as "surname name [patronymic]".
+// A surname may be hyphenated or consist of several words, in that case the last two words are the name and the patronymic.
+func ParseFullName(s string) (FullName, error) {
+	// Fields also splits on non-breaking spaces.
+	parts := strings.Fields(s)
+	for _, part := range parts {
+		if !isNamePart(part) {
+			return FullName{}, fmt.Errorf("%w: %q", ErrNameParse, s)
+		}
+	}
+
+	switch len(parts) {
+	case 0, 1:
+		return FullName{}, fmt.Errorf("%w: %q", ErrNameParse, s)
+	case 2:
+		return FullName{Surname: parts[0], Name: parts[1]}, nil
+	default:
+		n := len(parts)
+		return FullName{
+			Surname:    strings.Join(parts[:n-2], " "),
+			Name:       parts[n-2],
+			Patronymic: parts[n-1],
+		}, nil
+	}
+}
+
+// ShortName returns the name in the format of the teacher list, e.g. "Горобець С.М.".
+func (n FullName) ShortName() string {
+	short := n.Surname + " " + initial(n.Name)
+	if n.Patronymic != "" {
+		short += initial(n.Patronymic)
+	}
+	return short
+}
+
+// initial returns the first letter of name followed by a dot.
+func initial(name string) string {
+	for _, r := range name {
+		// The modifier letter apostrophe is a letter too, skip it.
+		if unicode.IsLetter(r) && !isApostrophe(r) {
+			return string(unicode.ToUpper(r)) + "."
+		}
+	}
+	return ""
+}
+
+// isNamePart reports whether s is a word of a name: letters with hyphens, apostrophes or dots.
+func isNamePart(s string) bool {
+	letters := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch {
+		case r == '-' || r == '.' || isApostrophe(r):
+		case unicode.IsLetter(r):
+			letters++
+		default:
+			return false
+		}
+	}
+	return letters > 0
+}
+
+// isApostrophe reports whether r is one of the characters used as an apostrophe in Ukrainian names.
+func isApostrophe(r rune) bool {
+	switch r {
+	case '\'', '’', 'ʼ', '‘', '`':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package psrozklad
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseFullName(t *testing.T) {
+	testCases := []struct {
+		name  string
+		want  FullName
+		short string
+	}{
+		{"Горобець Сергій Миколайович", FullName{"Горобець", "Сергій", "Миколайович"}, "Горобець С.М."},
+		{"Горобець Сергій  Миколайович ", FullName{"Горобець", "Сергій", "Миколайович"}, "Горобець С.М."},
+		{"Яценко Олександр", FullName{"Яценко", "Олександр", ""}, "Яценко О."},
+		{"Петренко-Іваненко Анна-Марія Петрівна", FullName{"Петренко-Іваненко", "Анна-Марія", "Петрівна"}, "Петренко-Іваненко А.П."},
+		{"Д'Аламбер Ян Ю", FullName{"Д'Аламбер", "Ян", "Ю"}, "Д'Аламбер Я.Ю."},
+		{"Ван дер Берг Олена Іванівна", FullName{"Ван дер Берг", "Олена", "Іванівна"}, "Ван дер Берг О.І."},
+		{"Кривонос О. М.", FullName{"Кривонос", "О.", "М."}, "Кривонос О.М."},
+		{"Мар’яненко Ірина ʼЮріївна", FullName{"Мар’яненко", "Ірина", "ʼЮріївна"}, "Мар’яненко І.Ю."},
+	}
+	for _, tC := range testCases {
+		got, err := ParseFullName(tC.name)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tC.name, err)
+			continue
+		}
+		if got != tC.want {
+			t.Errorf("%q: want: %+v, got: %+v", tC.name, tC.want, got)
+		}
+		if short := got.ShortName(); short != tC.short {
+			t.Errorf("%q: want short name %q, got %q", tC.name, tC.short, short)
+		}
+	}
+}
+
+func TestParseFullNameErrors(t *testing.T) {
+	for _, name := range []string{"", "  ", "Горобець", "Горобець 1 Миколайович", "Горобець - -"} {
+		if _, err := ParseFullName(name); !errors.Is(err, ErrNameParse) {
+			t.Errorf("%q: want ErrNameParse, got: %v", name, err)
+		}
+	}
+}
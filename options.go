@@ -0,0 +1,116 @@
+package psrozklad
+
+import (
+	"errors"
+	"time"
+)
+
+// Logger is used to log the requests of the Api. *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// Option configures an Api created by New.
+type Option func(*options) error
+
+// options holds the configuration collected from the options.
+type options struct {
+	client     Client
+	location   *time.Location
+	userAgent  string
+	encoding   Encoding
+	cache      *Cache
+	diskCache  *DiskCache
+	logger     Logger
+	retry      *RetryPolicy
+	limiter    *RateLimiter
+	missPolicy MissPolicy
+	bells      *BellSchedule
+}
+
+// WithHTTPClient sets the client used to do the http requests.
+func WithHTTPClient(c Client) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("http client is nil")
+		}
+		o.client = c
+		return nil
+	}
+}
+
+// WithTimezone sets the location of the lesson times, Kyiv by default.
+func WithTimezone(loc *time.Location) Option {
+	return func(o *options) error {
+		if loc == nil {
+			return errors.New("timezone is nil")
+		}
+		o.location = loc
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests.
+func WithUserAgent(ua string) Option {
+	return func(o *options) error {
+		o.userAgent = ua
+		return nil
+	}
+}
+
+// WithEncoding sets the coding_mode the API responds in.
+func WithEncoding(e Encoding) Option {
+	return func(o *options) error {
+		if e != EncodingUTF8 && e != EncodingWindows1251 {
+			return errors.New("unknown encoding: " + string(e))
+		}
+		o.encoding = e
+		return nil
+	}
+}
+
+// WithCache keeps responses in the in-memory cache c.
+func WithCache(c *Cache) Option {
+	return func(o *options) error {
+		o.cache = c
+		return nil
+	}
+}
+
+// WithDiskCache keeps responses in the disk cache c.
+func WithDiskCache(c *DiskCache) Option {
+	return func(o *options) error {
+		o.diskCache = c
+		return nil
+	}
+}
+
+// WithLogger logs the requests to l.
+func WithLogger(l Logger) Option {
+	return func(o *options) error {
+		o.logger = l
+		return nil
+	}
+}
+
+// WithRetryPolicy retries failed requests according to p.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) error {
+		if p.MaxAttempts < 1 {
+			return errors.New("retry policy needs at least one attempt")
+		}
+		if p.BaseDelay < 0 || p.MaxDelay < 0 || p.Jitter < 0 || p.Jitter > 1 {
+			return errors.New("invalid retry policy")
+		}
+		o.retry = &p
+		return nil
+	}
+}
+
+// WithRateLimiter passes every request through l.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(o *options) error {
+		o.limiter = l
+		return nil
+	}
+}
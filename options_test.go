@@ -0,0 +1,96 @@
+package psrozklad
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// headerHttpClient records the last request and returns body.
+type headerHttpClient struct {
+	body []byte
+	req  *http.Request
+}
+
+func (h *headerHttpClient) Do(req *http.Request) (*http.Response, error) {
+	h.req = req
+	return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Body: io.NopCloser(bytes.NewReader(h.body))}, nil
+}
+
+// bufLogger collects the logged messages.
+type bufLogger struct {
+	strings.Builder
+}
+
+func (b *bufLogger) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(b, format+"\n", v...)
+}
+
+func TestOptions(t *testing.T) {
+	// "Фізмат" in Windows-1251.
+	body := append([]byte(`{"psrozklad_export": {"departments": [{"name": "`), 0xD4, 0xB3, 0xE7, 0xEC, 0xE0, 0xF2)
+	body = append(body, []byte(`", "objects": [{"name": "21", "ID": "11"}]}], "code": "0"}}`)...)
+	client := &headerHttpClient{body: body}
+	logger := &bufLogger{}
+	kyiv := time.FixedZone("EET", 2*60*60)
+
+	api := newTestApi(t,
+		WithHTTPClient(client),
+		WithUserAgent("rozklad-bot/1.0"),
+		WithEncoding(EncodingWindows1251),
+		WithLogger(logger),
+		WithTimezone(kyiv),
+		WithCache(NewCache(DefaultCacheConfig)),
+		WithRetryPolicy(DefaultRetryPolicy),
+		WithRateLimiter(NewRateLimiter(10, 1, 1)),
+	)
+
+	groups, err := api.GetGroups()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Departament != "Фізмат" {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+	if ua := client.req.Header.Get("User-Agent"); ua != "rozklad-bot/1.0" {
+		t.Errorf("want user agent, got %q", ua)
+	}
+	if !strings.HasSuffix(api.BaseUri, "coding_mode=WINDOWS-1251") {
+		t.Errorf("unexpected base uri: %v", api.BaseUri)
+	}
+	if logger.Len() == 0 {
+		t.Errorf("want request to be logged")
+	}
+	if api.Location != kyiv || api.Cache == nil {
+		t.Errorf("options not applied: %+v", api)
+	}
+	rc, ok := api.HttpClient.(*RetryClient)
+	if !ok {
+		t.Fatalf("want *RetryClient, got %T", api.HttpClient)
+	}
+	if lc, ok := rc.Client.(*LimitedClient); !ok || lc.Client != client {
+		t.Errorf("want limited client wrapping the http client, got %T", rc.Client)
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		desc string
+		opt  Option
+	}{
+		{"nil client", WithHTTPClient(nil)},
+		{"nil timezone", WithTimezone(nil)},
+		{"unknown encoding", WithEncoding("KOI8-U")},
+		{"no attempts", WithRetryPolicy(RetryPolicy{})},
+		{"negative jitter", WithRetryPolicy(RetryPolicy{MaxAttempts: 2, Jitter: -1})},
+	}
+	for _, tC := range testCases {
+		if _, err := New("https://dekanat.zu.edu.ua/", tC.opt); err == nil {
+			t.Errorf("%s: want error", tC.desc)
+		}
+	}
+}
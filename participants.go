@@ -0,0 +1,36 @@
+package psrozklad
+
+// sameTeacher reports whether a and b are the same teacher, by ID if both have one and by short name otherwise.
+func sameTeacher(a, b Teacher) bool {
+	if a.Id != 0 && b.Id != 0 {
+		return a.Id == b.Id
+	}
+	return a.ShortName != "" && sameName(a.ShortName, b.ShortName)
+}
+
+// HasTeacher reports whether the teacher teaches the lesson, as the main teacher or as a co-teacher.
+func (l Lesson) HasTeacher(teacher Teacher) bool {
+	for _, t := range l.Teachers {
+		if sameTeacher(t, teacher) {
+			return true
+		}
+	}
+	// Unresolved teachers are only known by their text.
+	for _, ref := range append([]Ref{l.TeacherRef}, l.AdditionalTeacherRefs...) {
+		if ref.missing() && teacher.ShortName != "" && sameName(ref.Raw, teacher.ShortName) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterForTeacher returns the lessons taught by the teacher, including the lessons where they are a co-teacher.
+func FilterForTeacher(lessons []Lesson, teacher Teacher) []Lesson {
+	var filtered []Lesson
+	for _, lesson := range lessons {
+		if lesson.HasTeacher(teacher) {
+			filtered = append(filtered, lesson)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,97 @@
+package psrozklad
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoTeacher(t *testing.T) {
+	gorobets := Teacher{ShortName: "Горобець С.М.", Id: 420}
+	yatsenko := Teacher{ShortName: "Яценко О.С.", P: "Яценко", I: "Олександр", B: "Сергійович", Id: 486}
+
+	// The teacher-mode timetable of the co-teacher keeps the main teacher.
+	body := `{"psrozklad_export": {"roz_items": [{"object": "Яценко Олександр Сергійович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "13:40-15:00",
+		"teacher": "Горобець С.М.", "teachers_add": "Яценко О.С.", "group": "22Бд-СОмат", "type": "Лаб"}], "code": "0"}}`
+	api := newTestApi(t, WithHTTPClient(&MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}))
+	api.Directory.SetTeachers([]Teacher{gorobets, yatsenko})
+
+	lessons, err := api.GetLessons(yatsenko, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lesson := lessons[0]
+	if lesson.Teacher != gorobets || lesson.TeacherRef != (Ref{Raw: "Горобець С.М.", Resolved: true}) {
+		t.Errorf("unexpected main teacher: %+v, %+v", lesson.Teacher, lesson.TeacherRef)
+	}
+	if want := []Teacher{gorobets, yatsenko}; !reflect.DeepEqual(lesson.Teachers, want) {
+		t.Errorf("unexpected teachers: %+v", lesson.Teachers)
+	}
+
+	// The requested teacher is added when teachers_add doesn't list them.
+	body = `{"psrozklad_export": {"roz_items": [{"object": "Яценко Олександр Сергійович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "13:40-15:00",
+		"teacher": "Горобець С.М.", "group": "22Бд-СОмат"}], "code": "0"}}`
+	api.HttpClient = &MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}
+	lessons, err = api.GetLessons(yatsenko, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []Teacher{gorobets, yatsenko}; !reflect.DeepEqual(lessons[0].Teachers, want) {
+		t.Errorf("unexpected teachers: %+v", lessons[0].Teachers)
+	}
+
+	// Unresolved teachers are only in the refs.
+	body = `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "13:40-15:00",
+		"teacher": "Новенький Н.Н.", "teachers_add": "Яценко О.С., Старенький С.С."}], "code": "0"}}`
+	api.HttpClient = &MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}
+	lessons, err = api.GetLessons(Group{Name: "21Бд-СОмат", Id: 11}, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lesson = lessons[0]
+	if want := []Teacher{yatsenko}; !reflect.DeepEqual(lesson.Teachers, want) || !reflect.DeepEqual(lesson.AdditionalTeachers, want) {
+		t.Errorf("unexpected teachers: %+v, %+v", lesson.Teachers, lesson.AdditionalTeachers)
+	}
+	if len(lesson.AdditionalTeacherRefs) != 2 || lesson.AdditionalTeacherRefs[1] != (Ref{Raw: "Старенький С.С."}) {
+		t.Errorf("unexpected references: %+v", lesson.AdditionalTeacherRefs)
+	}
+	if !lesson.HasTeacher(Teacher{ShortName: "Новенький Н.Н."}) || lesson.HasTeacher(Teacher{}) {
+		t.Errorf("unexpected participants of %+v", lesson)
+	}
+}
+
+func TestFilterForTeacher(t *testing.T) {
+	gorobets := Teacher{ShortName: "Горобець С.М.", Id: 420}
+	yatsenko := Teacher{ShortName: "Яценко О.С.", Id: 486}
+	lessons := []Lesson{
+		{Number: 1, Teacher: gorobets, Teachers: []Teacher{gorobets}},
+		{Number: 2, Teacher: gorobets, Teachers: []Teacher{gorobets, yatsenko}},
+		{Number: 3, TeacherRef: Ref{Raw: "Яценко О.С."}},
+		{Number: 4},
+	}
+
+	testCases := []struct {
+		desc    string
+		teacher Teacher
+		want    []int
+	}{
+		{"main teacher", gorobets, []int{1, 2}},
+		{"co-teacher and unresolved", yatsenko, []int{2, 3}},
+		{"by name", Teacher{ShortName: "горобець с.м."}, []int{1, 2}},
+		{"nobody", Teacher{Id: 1}, nil},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var got []int
+			for _, lesson := range FilterForTeacher(lessons, tC.teacher) {
+				got = append(got, lesson.Number)
+			}
+			if !reflect.DeepEqual(got, tC.want) {
+				t.Errorf("want %v, got %v", tC.want, got)
+			}
+		})
+	}
+}
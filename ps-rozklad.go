@@ -2,125 +2,355 @@
 package psrozklad
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
+
+	// Embed the timezone database so Europe/Kyiv is available on every system.
+	_ "time/tzdata"
 )
 
 type Api struct {
 	BaseUri    string
 	HttpClient Client
+	// Directory holds rooms, groups and teachers used to resolve lessons.
+	// It is created on first use if nil.
+	Directory *Directory
+	// Cache keeps responses in memory if not nil.
+	Cache *Cache
+	// DiskCache keeps responses on disk if not nil.
+	DiskCache *DiskCache
+	// Location is the timezone of the lesson times, Kyiv if nil.
+	Location *time.Location
+	// UserAgent is sent with every request if not empty.
+	UserAgent string
+	// Encoding is the coding_mode of the responses, UTF-8 if empty.
+	Encoding Encoding
+	// Logger logs the requests if not nil.
+	Logger Logger
+	// MissPolicy decides what happens when a lesson names an object missing in the Directory.
+	MissPolicy MissPolicy
+	// Bells is the bell schedule of the university, the searches aligned to the lesson slots need it.
+	// LearnBellSchedule makes one from the lessons of a few busy groups, if they agree on the times.
+	Bells *BellSchedule
+
+	// dirOnce guards the creation of the Directory of an Api that was not made by New.
+	dirOnce sync.Once
 }
 
 type Client interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-var (
-	Rooms    map[string]Room
-	Teachers map[string]Teacher
-	Groups   map[string]Group
-)
+// New creates a new Api for the ps-rozklad installation at baseURL, e.g. "https://dekanat.zu.edu.ua/".
+func New(baseURL string, opts ...Option) (*Api, error) {
+	// Collect the options.
+	o := options{client: http.DefaultClient, encoding: EncodingUTF8}
+	for _, opt := range opts {
+		err := opt(&o)
+		if err != nil {
+			return nil, fmt.Errorf("invalid option: %w", err)
+		}
+	}
+
+	// Validate and normalize the base URL.
+	base, err := normalizeBaseURL(baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new Api struct.
+	a := &Api{
+		BaseUri:    base + "cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=" + string(o.encoding),
+		HttpClient: o.client,
+		Directory:  NewDirectory(),
+		Cache:      o.cache,
+		DiskCache:  o.diskCache,
+		Location:   o.location,
+		UserAgent:  o.userAgent,
+		Encoding:   o.encoding,
+		Logger:     o.logger,
+		MissPolicy: o.missPolicy,
+		Bells:      o.bells,
+	}
+
+	// Wrap the client, the limiter goes inside the retries so that every attempt is limited.
+	if o.limiter != nil {
+		a.SetRateLimiter(o.limiter)
+	}
+	if o.retry != nil {
+		a.SetRetryPolicy(*o.retry)
+	}
+
+	return a, nil
+}
+
+// normalizeBaseURL checks that baseURL is an http(s) URL and returns it with a trailing slash and without the script path.
+func normalizeBaseURL(baseURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %q: %w", baseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid base url %q: scheme must be http or https", baseURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("invalid base url %q: no host", baseURL)
+	}
+
+	// Drop the query and the script path, they are added by the Api.
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "cgi-bin/timetable_export.cgi")
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	return u.String(), nil
+}
+
+// Kyiv is the default timezone of the lesson times.
+var Kyiv = loadKyiv()
+
+// loadKyiv loads the Europe/Kyiv timezone, falling back to its old name and then to a fixed EET zone.
+func loadKyiv() *time.Location {
+	for _, name := range []string{"Europe/Kyiv", "Europe/Kiev"} {
+		loc, err := time.LoadLocation(name)
+		if err == nil {
+			return loc
+		}
+	}
+	return time.FixedZone("EET", 2*60*60)
+}
+
+// location returns the timezone of the lesson times.
+func (a *Api) location() *time.Location {
+	if a.Location == nil {
+		return Kyiv
+	}
+	return a.Location
+}
+
+// logf logs a message if the Api has a logger.
+func (a *Api) logf(format string, v ...interface{}) {
+	if a.Logger != nil {
+		a.Logger.Printf(format, v...)
+	}
+}
+
+// dir returns the directory of the Api, creating it if needed.
+// It is safe for concurrent use, as long as the Directory field is not changed at the same time.
+func (a *Api) dir() *Directory {
+	a.dirOnce.Do(func() {
+		if a.Directory == nil {
+			a.Directory = NewDirectory()
+		}
+	})
+	return a.Directory
+}
+
+// listURL returns the URL of the list of objects of type obj: "group", "room" or "teacher".
+func (a *Api) listURL(obj string) string {
+	return a.BaseUri + "&req_type=obj_list&req_mode=" + obj + "&show_ID=yes"
+}
+
+// getJSON does a GET request to url and decodes the JSON response into v.
+// If the API is unreachable and the disk cache has a copy of the response, it is decoded into v and a *StaleError is returned.
+func (a *Api) getJSON(ctx context.Context, url string, v interface{}) error {
+	// Serve fresh responses from the disk cache.
+	if body, ok := a.DiskCache.fresh(url); ok && json.Unmarshal(body, v) == nil {
+		return nil
+	}
+	return a.fetchJSON(ctx, url, v)
+}
+
+// fetchJSON is like getJSON but always asks the API, the disk cache is only used if the API is unreachable.
+func (a *Api) fetchJSON(ctx context.Context, url string, v interface{}) error {
+	body, err := a.fetch(ctx, url)
+	if err != nil {
+		// Fall back to the last known response if the API is unreachable.
+		if unreachable(err) {
+			if body, fetchedAt, ok := a.DiskCache.stale(url); ok && json.Unmarshal(body, v) == nil {
+				return &StaleError{FetchedAt: fetchedAt, Err: err}
+			}
+		}
+		return err
+	}
+
+	// Decode the JSON response into v.
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+	}
+
+	// Keep a copy of the response on disk.
+	a.DiskCache.store(url, body)
+
+	return nil
+}
+
+// fetch does a GET request to url and returns the body of a valid response.
+func (a *Api) fetch(ctx context.Context, url string) ([]byte, error) {
+	// Create a new HTTP request.
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+
+	if a.UserAgent != "" {
+		req.Header.Set("User-Agent", a.UserAgent)
+	}
 
-// New creates a new Api struct.
-func New(BaseUri string) Api {
-	// Add some query parameters to the base URI.
-	BaseUri += "cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8"
+	// Execute the HTTP request.
+	resp, err := a.HttpClient.Do(req)
+	if err != nil {
+		a.logf("GET %s: %v", url, err)
+		return nil, fmt.Errorf("failed to do http request: %w", err)
+	}
+	defer resp.Body.Close()
+	a.logf("GET %s: %s", url, resp.Status)
+
+	// Don't try to decode error pages.
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	// Read the whole body, so it can be stored.
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read http response: %w", err)
+	}
+	body = a.Encoding.decode(body)
+
+	// Check the status reported by the API.
+	var status struct {
+		Export exportStatus `json:"psrozklad_export"`
+	}
+	err = json.Unmarshal(body, &status)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+	}
+	err = status.Export.err()
+	if err != nil {
+		return nil, err
+	}
 
-	// Create a new Api struct and return it.
-	return Api{BaseUri: BaseUri, HttpClient: http.DefaultClient}
+	return body, nil
 }
 
 // Initialize rooms,groups,teschers
 func (a *Api) Init() error {
+	return a.InitContext(context.Background())
+}
+
+// InitContext is like Init but uses ctx for the http requests.
+// If some lists are served stale from the disk cache, the directory is filled and a *StaleError is returned.
+func (a *Api) InitContext(ctx context.Context) error {
+	// Keep the first stale error, stale lists are still usable.
+	var stale error
+
 	// Initialize the groups.
-	err := a.InitGroups()
-	if err != nil {
+	err := a.InitGroupsContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the groups failed to initialize.
-		return fmt.Errorf("failed to init: %v", err)
+		return fmt.Errorf("failed to init: %w", err)
+	}
+	if stale == nil {
+		stale = err
 	}
 
 	// Initialize the rooms.
-	err = a.InitRooms()
-	if err != nil {
+	err = a.InitRoomsContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the rooms failed to initialize.
-		return fmt.Errorf("failed to init: %v", err)
+		return fmt.Errorf("failed to init: %w", err)
+	}
+	if stale == nil {
+		stale = err
 	}
 
 	// Initialize the teachers.
-	err = a.InitTeachers()
-	if err != nil {
+	err = a.InitTeachersContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the teachers failed to initialize.
-		return fmt.Errorf("failed to init: %v", err)
+		return fmt.Errorf("failed to init: %w", err)
+	}
+	if stale == nil {
+		stale = err
 	}
 
-	// Return nil if all of the initialization functions succeeded.
-	return nil
+	// Return nil if all of the initialization functions succeeded, or the first *StaleError.
+	return stale
 }
 
-// InitRooms initializes the Rooms map.
+// InitRooms fills the rooms of the Api directory.
 func (a *Api) InitRooms() error {
-	// Create a new empty map.
-	Rooms = make(map[string]Room)
+	return a.InitRoomsContext(context.Background())
+}
 
+// InitRoomsContext is like InitRooms but uses ctx for the http request.
+// If the list is served stale from the disk cache, the directory is filled and the *StaleError is returned.
+func (a *Api) InitRoomsContext(ctx context.Context) error {
 	// Get a list of all of the rooms from the API.
-	rooms, err := a.GetRooms()
-	if err != nil {
+	rooms, err := a.GetRoomsContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the GetRooms() function failed.
-		return fmt.Errorf("failed to get rooms: %v", err)
+		return fmt.Errorf("failed to get rooms: %w", err)
 	}
 
-	// Iterate over the list of rooms and add each room to the Rooms map.
-	for _, room := range rooms {
-		// The key of each entry in the map is the room's name and block, concatenated with a slash.
-		key := room.Name + "/" + room.Block
-		Rooms[strings.ToLower(key)] = room
-	}
+	// Replace the rooms in the directory.
+	a.dir().SetRooms(rooms)
 
-	// Return nil, indicating that the function was successful.
-	return nil
+	// Return nil, indicating that the function was successful, or the *StaleError if the list is stale.
+	return err
 }
 
-// InitGroups initializes the Groups map.
+// InitGroups fills the groups of the Api directory.
 func (a *Api) InitGroups() error {
-	// Create a new empty map.
-	Groups = make(map[string]Group)
+	return a.InitGroupsContext(context.Background())
+}
 
+// InitGroupsContext is like InitGroups but uses ctx for the http request.
+// If the list is served stale from the disk cache, the directory is filled and the *StaleError is returned.
+func (a *Api) InitGroupsContext(ctx context.Context) error {
 	// Get a list of all of the groups from the API.
-	groups, err := a.GetGroups()
-	if err != nil {
+	groups, err := a.GetGroupsContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the GetGroups() function failed.
-		return fmt.Errorf("failed to get groups: %v", err)
+		return fmt.Errorf("failed to get groups: %w", err)
 	}
 
-	// Iterate over the list of groups and add each group to the Groups map.
-	for _, group := range groups {
-		// The key of each entry in the map is the group's name.
-		Groups[strings.ToLower(group.Name)] = group
-	}
+	// Replace the groups in the directory.
+	a.dir().SetGroups(groups)
 
-	// Return nil, indicating that the function was successful.
-	return nil
+	// Return nil, indicating that the function was successful, or the *StaleError if the list is stale.
+	return err
 }
 
-// InitTeachers initializes the Teachers map.
+// InitTeachers fills the teachers of the Api directory.
 func (a *Api) InitTeachers() error {
-	// Create a new empty map.
-	Teachers = make(map[string]Teacher)
+	return a.InitTeachersContext(context.Background())
+}
 
+// InitTeachersContext is like InitTeachers but uses ctx for the http request.
+// If the list is served stale from the disk cache, the directory is filled and the *StaleError is returned.
+func (a *Api) InitTeachersContext(ctx context.Context) error {
 	// Get a list of all of the teachers from the API.
-	teachers, err := a.GetTeachers()
-	if err != nil {
+	teachers, err := a.GetTeachersContext(ctx)
+	if err != nil && !IsStale(err) {
 		// Return an error if the GetTeachers() function failed.
-		return fmt.Errorf("failed to get teachers: %v", err)
+		return fmt.Errorf("failed to get teachers: %w", err)
 	}
 
-	// Iterate over the list of teachers and add each teacher to the Teachers map.
-	for _, teacher := range teachers {
-		// The key of each entry in the map is the teacher's short name.
-		Teachers[strings.ToLower(teacher.ShortName)] = teacher
-	}
+	// Replace the teachers in the directory.
+	a.dir().SetTeachers(teachers)
 
-	// Return nil, indicating that the function was successful.
-	return nil
+	// Return nil, indicating that the function was successful, or the *StaleError if the list is stale.
+	return err
 }
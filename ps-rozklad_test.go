@@ -1,8 +1,10 @@
 package psrozklad
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"time"
 )
 
 type MockHttpClient struct {
@@ -10,14 +12,89 @@ type MockHttpClient struct {
 }
 
 func (m *MockHttpClient) Do(req *http.Request) (*http.Response, error) {
+	// Most test responses only set the body, treat them as successful.
+	if m.resp.StatusCode == 0 {
+		m.resp.StatusCode = http.StatusOK
+	}
 	return &m.resp, nil
 }
 
+// newTestApi creates an Api for "https://dekanat.zu.edu.ua/".
+func newTestApi(t *testing.T, opts ...Option) *Api {
+	t.Helper()
+	api, err := New("https://dekanat.zu.edu.ua/", opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return api
+}
+
 func TestNew(t *testing.T) {
-	baseUri := "https://dekanat.zu.edu.ua/"
-	want := Api{BaseUri: baseUri + "cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8", HttpClient: http.DefaultClient}
-	got := New(baseUri)
-	if got != want {
-		t.Errorf("want: %v, got: %v", want, got)
+	testCases := []struct {
+		baseUri string
+		want    string
+		wantErr bool
+	}{
+		{baseUri: "https://dekanat.zu.edu.ua/", want: "https://dekanat.zu.edu.ua/cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8"},
+		{baseUri: "https://dekanat.zu.edu.ua", want: "https://dekanat.zu.edu.ua/cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8"},
+		{baseUri: " http://rozklad.example.com/dekanat ", want: "http://rozklad.example.com/dekanat/cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8"},
+		{baseUri: "https://dekanat.zu.edu.ua/cgi-bin/timetable_export.cgi?req_format=json", want: "https://dekanat.zu.edu.ua/cgi-bin/timetable_export.cgi?&req_format=json&coding_mode=UTF8"},
+		{baseUri: "", wantErr: true},
+		{baseUri: "dekanat.zu.edu.ua", wantErr: true},
+		{baseUri: "ftp://dekanat.zu.edu.ua/", wantErr: true},
+		{baseUri: "https://", wantErr: true},
+	}
+	for _, tC := range testCases {
+		got, err := New(tC.baseUri)
+		if tC.wantErr {
+			if err == nil {
+				t.Errorf("%q: want error", tC.baseUri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tC.baseUri, err)
+			continue
+		}
+		if got.BaseUri != tC.want {
+			t.Errorf("%q: want: %v, got: %v", tC.baseUri, tC.want, got.BaseUri)
+		}
+		if got.HttpClient != http.DefaultClient || got.Directory == nil {
+			t.Errorf("%q: unexpected defaults: %+v", tC.baseUri, got)
+		}
+	}
+}
+
+// ctxHttpClient fails with the request context error, like http.Client does.
+type ctxHttpClient struct{}
+
+func (ctxHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestContextCanceled(t *testing.T) {
+	api := newTestApi(t)
+	api.HttpClient = ctxHttpClient{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := api.GetLessonsContext(ctx, Group{}, time.Time{}, time.Time{}); err == nil {
+		t.Errorf("GetLessonsContext: want error for canceled context")
+	}
+	if _, err := api.GetGroupsContext(ctx); err == nil {
+		t.Errorf("GetGroupsContext: want error for canceled context")
+	}
+	if _, err := api.GetRoomsContext(ctx); err == nil {
+		t.Errorf("GetRoomsContext: want error for canceled context")
+	}
+	if _, err := api.GetTeachersContext(ctx); err == nil {
+		t.Errorf("GetTeachersContext: want error for canceled context")
+	}
+	if err := api.InitContext(ctx); err == nil {
+		t.Errorf("InitContext: want error for canceled context")
 	}
 }
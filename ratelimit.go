@@ -0,0 +1,163 @@
+package psrozklad
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiter with a limit of concurrent requests.
+// It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	sem chan struct{}
+}
+
+// NewRateLimiter creates a limiter that allows rps requests per second with bursts of up to burst requests,
+// and no more than maxConcurrent requests at once. Zero or negative values disable the corresponding limit.
+func NewRateLimiter(rps float64, burst, maxConcurrent int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	l := &RateLimiter{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+	}
+	if maxConcurrent > 0 {
+		l.sem = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// Acquire waits for a free slot and a token. The returned function must be called when the request is finished.
+func (l *RateLimiter) Acquire(ctx context.Context) (release func(), err error) {
+	// Take a concurrency slot first, so that waiting requests don't use up tokens.
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			if l.sem != nil {
+				<-l.sem
+			}
+		})
+	}
+
+	// Then wait for a token.
+	err = l.Wait(ctx)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// Wait waits until a token is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return ctx.Err()
+	}
+
+	// Reserve a token, possibly from the future.
+	l.mu.Lock()
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return ctx.Err()
+	}
+
+	// Wait for the reserved token.
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// LimitedClient is a Client that passes every request through a RateLimiter.
+type LimitedClient struct {
+	Client  Client
+	Limiter *RateLimiter
+}
+
+// SetRateLimiter makes every request of the Api go through l.
+// If the Api retries requests, every attempt is limited.
+func (a *Api) SetRateLimiter(l *RateLimiter) {
+	// Limit the attempts made by a retry client, not the whole retry loop.
+	if rc, ok := a.HttpClient.(*RetryClient); ok {
+		rc.Client = limitClient(rc.Client, l)
+		return
+	}
+	a.HttpClient = limitClient(a.HttpClient, l)
+}
+
+// limitClient wraps c with l, replacing the limiter if c is already limited.
+func limitClient(c Client, l *RateLimiter) Client {
+	if lc, ok := c.(*LimitedClient); ok {
+		lc.Limiter = l
+		return lc
+	}
+	return &LimitedClient{Client: c, Limiter: l}
+}
+
+// Do waits for the limiter and executes the request.
+// The concurrency slot is held until the response body is closed.
+func (c *LimitedClient) Do(req *http.Request) (*http.Response, error) {
+	release, err := c.Limiter.Acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		release()
+		return resp, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody calls release when the body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
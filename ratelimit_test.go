@@ -0,0 +1,97 @@
+package psrozklad
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowHttpClient tracks how many requests run at once.
+type slowHttpClient struct {
+	running, max int32
+}
+
+func (s *slowHttpClient) Do(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt32(&s.running, 1)
+	for {
+		m := atomic.LoadInt32(&s.max)
+		if n <= m || atomic.CompareAndSwapInt32(&s.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	atomic.AddInt32(&s.running, -1)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(`{"psrozklad_export": {"departments": [], "code": "0"}}`)),
+	}, nil
+}
+
+func TestRateLimiterConcurrency(t *testing.T) {
+	client := &slowHttpClient{}
+	api := newTestApi(t)
+	api.HttpClient = client
+	api.SetRateLimiter(NewRateLimiter(0, 1, 2))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := api.GetGroups(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if client.max > 2 {
+		t.Errorf("want at most 2 concurrent requests, got %d", client.max)
+	}
+}
+
+func TestRateLimiterRate(t *testing.T) {
+	l := NewRateLimiter(100, 1, 0)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The first token is free, the other three take 10ms each.
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("limiter too fast: %v", elapsed)
+	}
+}
+
+func TestRateLimiterContext(t *testing.T) {
+	l := NewRateLimiter(0.001, 1, 0)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want context.DeadlineExceeded, got: %v", err)
+	}
+}
+
+func TestSetRateLimiterWithRetry(t *testing.T) {
+	api := newTestApi(t)
+	api.SetRetryPolicy(DefaultRetryPolicy)
+	api.SetRateLimiter(NewRateLimiter(1, 1, 1))
+
+	rc, ok := api.HttpClient.(*RetryClient)
+	if !ok {
+		t.Fatalf("want *RetryClient, got %T", api.HttpClient)
+	}
+	if _, ok := rc.Client.(*LimitedClient); !ok {
+		t.Errorf("want the limiter inside the retry client, got %T", rc.Client)
+	}
+}
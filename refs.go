@@ -0,0 +1,117 @@
+package psrozklad
+
+import (
+	"context"
+	"time"
+)
+
+// Ref is a reference of a lesson to a teacher, room or group as the API wrote it.
+type Ref struct {
+	// Raw is the text of the API, e.g. "Горобець С.М." or "320/№1".
+	Raw string
+	// Resolved reports whether the object was found in the directory.
+	Resolved bool
+}
+
+// missing reports whether the reference names an object that is not in the directory.
+func (r Ref) missing() bool {
+	return r.Raw != "" && !r.Resolved
+}
+
+// Resolved reports whether every teacher, room and group of the lesson was found in the directory.
+func (l Lesson) Resolved() bool {
+	if l.TeacherRef.missing() || l.RoomRef.missing() {
+		return false
+	}
+	for _, ref := range l.AdditionalTeacherRefs {
+		if ref.missing() {
+			return false
+		}
+	}
+	for _, ref := range l.GroupRefs {
+		if ref.missing() {
+			return false
+		}
+	}
+	return true
+}
+
+// resolvedLessons reports whether every lesson is resolved.
+func resolvedLessons(lessons []Lesson) bool {
+	for _, lesson := range lessons {
+		if !lesson.Resolved() {
+			return false
+		}
+	}
+	return true
+}
+
+// MissPolicy decides what happens when a lesson names an object that is not in the directory.
+type MissPolicy struct {
+	// Refresh reloads the list of the missing objects from the API and converts the lessons again.
+	Refresh bool
+	// MinInterval is the minimum time between two refreshes of the same list, DefaultMissInterval if zero.
+	// Objects missing upstream too, e.g. co-teachers of other departments, would reload the list on every fetch otherwise.
+	MinInterval time.Duration
+}
+
+// DefaultMissInterval is the MinInterval of a MissPolicy that doesn't set one.
+const DefaultMissInterval = time.Minute
+
+// minInterval returns the MinInterval or its default.
+func (p MissPolicy) minInterval() time.Duration {
+	if p.MinInterval == 0 {
+		return DefaultMissInterval
+	}
+	return p.MinInterval
+}
+
+// WithMissPolicy sets the policy for objects missing in the directory.
+func WithMissPolicy(p MissPolicy) Option {
+	return func(o *options) error {
+		o.missPolicy = p
+		return nil
+	}
+}
+
+// refreshMissing refreshes the lists of the objects missing in the lessons according to the MissPolicy.
+// It reports whether any list was refreshed.
+func (a *Api) refreshMissing(ctx context.Context, lessons []Lesson) bool {
+	if !a.MissPolicy.Refresh {
+		return false
+	}
+
+	// Find out which kinds of objects are missing.
+	var teachers, rooms, groups bool
+	for _, lesson := range lessons {
+		teachers = teachers || lesson.TeacherRef.missing()
+		rooms = rooms || lesson.RoomRef.missing()
+		for _, ref := range lesson.AdditionalTeacherRefs {
+			teachers = teachers || ref.missing()
+		}
+		for _, ref := range lesson.GroupRefs {
+			groups = groups || ref.missing()
+		}
+	}
+
+	// Refresh the lists, a failed refresh just leaves the objects unresolved.
+	// The lists are removed from the memory and the disk cache first, so they are really fetched again.
+	d := a.dir()
+	var refreshed bool
+	if teachers && d.startRefresh("teacher", a.MissPolicy.minInterval()) {
+		a.Cache.remove(listKey("teacher"))
+		a.DiskCache.remove(a.listURL("teacher"))
+		refreshed = a.InitTeachersContext(ctx) == nil || refreshed
+	}
+	if rooms && d.startRefresh("room", a.MissPolicy.minInterval()) {
+		a.Cache.remove(listKey("room"))
+		a.DiskCache.remove(a.listURL("room"))
+		refreshed = a.InitRoomsContext(ctx) == nil || refreshed
+	}
+	if groups && d.startRefresh("group", a.MissPolicy.minInterval()) {
+		a.Cache.remove(listKey("group"))
+		a.DiskCache.remove(a.listURL("group"))
+		refreshed = a.InitGroupsContext(ctx) == nil || refreshed
+	}
+	return refreshed
+}
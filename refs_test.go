@@ -0,0 +1,139 @@
+package psrozklad
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// routeHttpClient returns the body of the first route contained in the request URL and counts the requests per route.
+type routeHttpClient struct {
+	routes []route
+	calls  map[string]int
+}
+
+type route struct {
+	contains, body string
+}
+
+func (r *routeHttpClient) Do(req *http.Request) (*http.Response, error) {
+	if r.calls == nil {
+		r.calls = make(map[string]int)
+	}
+	for _, rt := range r.routes {
+		if strings.Contains(req.URL.String(), rt.contains) {
+			r.calls[rt.contains]++
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(rt.body))}, nil
+		}
+	}
+	return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: http.NoBody}, nil
+}
+
+func TestUnresolvedRefs(t *testing.T) {
+	client := &routeHttpClient{routes: []route{
+		{"req_type=obj_list&req_mode=teacher", `{"psrozklad_export": {"departments": [{"name": "Кафедра", "objects": [{"name": "Новенький Н.Н.", "ID": "7"}]}], "code": "0"}}`},
+		{"req_mode=group", `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "teacher": "Новенький Н.Н.", "room": "999/№9"}], "code": "0"}}`},
+	}}
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+
+	api := newTestApi(t, WithHTTPClient(client))
+	api.Directory.SetGroups([]Group{group})
+
+	// Without a policy the references stay unresolved.
+	lessons, err := api.GetLessons(group, day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lesson := lessons[0]
+	if lesson.Resolved() || lesson.TeacherRef != (Ref{Raw: "Новенький Н.Н."}) || lesson.RoomRef != (Ref{Raw: "999/№9"}) {
+		t.Errorf("unexpected references: %+v, %+v", lesson.TeacherRef, lesson.RoomRef)
+	}
+	if len(lesson.GroupRefs) != 1 || !lesson.GroupRefs[0].Resolved {
+		t.Errorf("unexpected group references: %+v", lesson.GroupRefs)
+	}
+
+	// With the refresh policy the teacher list is loaded again, once per interval.
+	api.MissPolicy = MissPolicy{Refresh: true, MinInterval: time.Hour}
+	for i := 0; i < 2; i++ {
+		lessons, err = api.GetLessons(group, day, day)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		lesson = lessons[0]
+		if lesson.Teacher.Id != 7 || !lesson.TeacherRef.Resolved {
+			t.Errorf("teacher not resolved after refresh: %+v", lesson.TeacherRef)
+		}
+		// The room is not in any list, it stays unresolved.
+		if lesson.RoomRef.Resolved || lesson.Resolved() {
+			t.Errorf("room should stay unresolved: %+v", lesson.RoomRef)
+		}
+	}
+	if n := client.calls["req_type=obj_list&req_mode=teacher"]; n != 1 {
+		t.Errorf("want 1 teacher list request, got %d", n)
+	}
+}
+
+func TestUnresolvedRefsDiskCache(t *testing.T) {
+	teachers := route{"req_type=obj_list&req_mode=teacher", `{"psrozklad_export": {"departments": [], "code": "0"}}`}
+	client := &routeHttpClient{routes: []route{
+		teachers,
+		{"req_mode=group", `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "teacher": "Новенький Н.Н."}], "code": "0"}}`},
+	}}
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+
+	api := newTestApi(t, WithHTTPClient(client))
+	api.DiskCache, _ = NewDiskCache(t.TempDir())
+	api.DiskCache.MaxAge = time.Hour
+
+	// The old teacher list and the unresolved lessons are fresh on disk.
+	if err := api.InitTeachers(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lessons, err := api.GetLessons(group, day, day)
+	if err != nil || lessons[0].TeacherRef.Resolved {
+		t.Fatalf("unexpected result: %+v, %v", lessons, err)
+	}
+
+	// The teacher is added upstream, the refresh fetches both again despite the disk cache.
+	client.routes[0].body = `{"psrozklad_export": {"departments": [{"name": "Кафедра", "objects": [{"name": "Новенький Н.Н.", "ID": "7"}]}], "code": "0"}}`
+	api.MissPolicy = MissPolicy{Refresh: true}
+	lessons, err = api.GetLessons(group, day, day)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lessons[0].Teacher.Id != 7 || !lessons[0].TeacherRef.Resolved {
+		t.Errorf("teacher not resolved after refresh: %+v", lessons[0].TeacherRef)
+	}
+	if n := client.calls[teachers.contains]; n != 2 {
+		t.Errorf("want 2 teacher list requests, got %d", n)
+	}
+}
+
+func TestUnresolvedRefsDefaultInterval(t *testing.T) {
+	teachers := route{"req_type=obj_list&req_mode=teacher", `{"psrozklad_export": {"departments": [], "code": "0"}}`}
+	client := &routeHttpClient{routes: []route{
+		teachers,
+		{"OBJ_ID=11&", `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "teacher": "Чужий Ч.Ч."}], "code": "0"}}`},
+	}}
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	day := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+
+	// The teacher is not in the list upstream either, only the first miss reloads it.
+	api := newTestApi(t, WithHTTPClient(client), WithMissPolicy(MissPolicy{Refresh: true}))
+	for i := 0; i < 5; i++ {
+		if _, err := api.GetLessons(group, day, day); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := client.calls[teachers.contains]; n != 1 {
+		t.Errorf("want 1 teacher list request, got %d", n)
+	}
+	if n := client.calls["OBJ_ID=11&"]; n != 5 {
+		t.Errorf("want 5 timetable requests, got %d", n)
+	}
+}
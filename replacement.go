@@ -0,0 +1,175 @@
+package psrozklad
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ReplacementKind is the kind of a replacement notice.
+type ReplacementKind int
+
+const (
+	// ReplacementNone means the lesson has no replacement notice.
+	ReplacementNone ReplacementKind = iota
+	// ReplacementTeacher means only the teacher of the lesson is replaced.
+	ReplacementTeacher
+	// ReplacementLesson means the lesson replaces another lesson with its own title and type.
+	ReplacementLesson
+	// ReplacementUnknown means the notice could not be parsed, the text is kept in Replacement.Text.
+	ReplacementUnknown
+)
+
+func (k ReplacementKind) String() string {
+	switch k {
+	case ReplacementNone:
+		return "none"
+	case ReplacementTeacher:
+		return "teacher"
+	case ReplacementLesson:
+		return "lesson"
+	default:
+		return "unknown"
+	}
+}
+
+// Replacement describes a "Увага! Заміна!" notice of a lesson.
+type Replacement struct {
+	Title      string
+	Teacher    Teacher
+	Type       string
+	LessonType LessonType
+	Kind       ReplacementKind
+	// Text is the notice as the API returned it.
+	Text string
+}
+
+// ReplacementNotice is the result of parsing a replacement notice.
+type ReplacementNotice struct {
+	Kind ReplacementKind
+	// Teacher is the short name of the teacher of the notice, e.g. "Горобець С.М.".
+	Teacher string
+	// Title and Type are the title and the type of the original lesson, empty for ReplacementTeacher.
+	Title string
+	Type  string
+	// Rest is the text after "замість:", if any.
+	Rest string
+}
+
+// ParseReplacement parses a replacement notice. The known variants are
+//
+//	Увага! Заміна! Горобець С.М. Інженерна графіка Л замість:
+//	Увага! Заміна! Горобець С.М. замість: Яценко О.С.
+//
+// The "Увага!" word is optional, spaces may be regular or non-breaking and the initials may be separated by spaces.
+func ParseReplacement(text string) (ReplacementNotice, error) {
+	tokens := tokenizeReplacement(text)
+	fail := func(reason string) (ReplacementNotice, error) {
+		return ReplacementNotice{}, fmt.Errorf("%w: %s: %q", ErrReplacementParse, reason, text)
+	}
+
+	// Skip the "Увага!" and require the "Заміна!" keyword.
+	if len(tokens) > 0 && isWord(tokens[0], "увага") {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || !isWord(tokens[0], "заміна") {
+		return fail("no \"Заміна!\" keyword")
+	}
+	tokens = tokens[1:]
+
+	// Find the "замість" keyword, everything after it is the rest.
+	var notice ReplacementNotice
+	end := -1
+	for i, token := range tokens {
+		if isWord(token, "замість") {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return fail("no \"замість\" keyword")
+	}
+	notice.Rest = strings.Join(tokens[end+1:], " ")
+	tokens = tokens[:end]
+
+	// The teacher is the surname followed by the initials.
+	initials := -1
+	for i, token := range tokens {
+		if i > 0 && isInitials(token) {
+			initials = i
+			break
+		}
+	}
+	if initials == -1 {
+		return fail("no teacher")
+	}
+	name := strings.Join(tokens[:initials], " ") + " "
+	i := initials
+	for ; i < len(tokens) && isInitials(tokens[i]); i++ {
+		name += tokens[i]
+	}
+	notice.Teacher = name
+	tokens = tokens[i:]
+
+	// Without a title only the teacher is replaced.
+	if len(tokens) == 0 {
+		notice.Kind = ReplacementTeacher
+		return notice, nil
+	}
+
+	// The last token is the type if it looks like one.
+	notice.Kind = ReplacementLesson
+	last := strings.Trim(tokens[len(tokens)-1], "()")
+	if len(tokens) > 1 && ParseLessonType(last) != LessonUnknown {
+		notice.Type = last
+		tokens = tokens[:len(tokens)-1]
+	}
+	notice.Title = strings.Join(tokens, " ")
+
+	return notice, nil
+}
+
+// tokenizeReplacement splits text on any kind of space, including non-breaking ones.
+func tokenizeReplacement(text string) []string {
+	return strings.Fields(text)
+}
+
+// isWord reports whether token is word, ignoring case and punctuation around it.
+func isWord(token, word string) bool {
+	return strings.EqualFold(strings.TrimFunc(token, unicode.IsPunct), word)
+}
+
+// isInitials reports whether token consists of initials like "С." or "С.М.".
+func isInitials(token string) bool {
+	if token == "" {
+		return false
+	}
+	for token != "" {
+		r, size := utf8.DecodeRuneInString(token)
+		if !unicode.IsUpper(r) || !strings.HasPrefix(token[size:], ".") {
+			return false
+		}
+		token = token[size+1:]
+	}
+	return true
+}
+
+// convertReplacement converts a replacement notice to a Replacement, resolving the teacher in d.
+// Notices that can't be parsed are kept as ReplacementUnknown.
+func convertReplacement(d *Directory, text string) Replacement {
+	notice, err := ParseReplacement(text)
+	if err != nil {
+		return Replacement{Kind: ReplacementUnknown, Text: text}
+	}
+
+	replacement := Replacement{
+		Title:      notice.Title,
+		Type:       notice.Type,
+		LessonType: ParseLessonType(notice.Type),
+		Kind:       notice.Kind,
+		Text:       text,
+	}
+	replacement.Teacher, _ = d.Teacher(notice.Teacher)
+	return replacement
+}
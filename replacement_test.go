@@ -0,0 +1,77 @@
+package psrozklad
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseReplacement(t *testing.T) {
+	testCases := []struct {
+		desc string
+		text string
+		want ReplacementNotice
+	}{
+		{
+			desc: "lesson",
+			text: "Увага! Заміна! Горобець\u00a0С.М. Інженерна та комп‘ютерна графіка Л замість:",
+			want: ReplacementNotice{Kind: ReplacementLesson, Teacher: "Горобець С.М.", Title: "Інженерна та комп‘ютерна графіка", Type: "Л"},
+		},
+		{
+			desc: "teacher",
+			text: "Увага! Заміна! Яценко О.С. замість: Кривонос О.М.",
+			want: ReplacementNotice{Kind: ReplacementTeacher, Teacher: "Яценко О.С.", Rest: "Кривонос О.М."},
+		},
+		{
+			desc: "separated initials and parenthesized type",
+			text: "Заміна!  Яценко О. С. Комп‘ютерні мережі (Лаб) замість:",
+			want: ReplacementNotice{Kind: ReplacementLesson, Teacher: "Яценко О.С.", Title: "Комп‘ютерні мережі", Type: "Лаб"},
+		},
+		{
+			desc: "hyphenated surname without type",
+			text: "УВАГА! ЗАМІНА! Петренко-Іваненко А.Б. Фізичне виховання замість:",
+			want: ReplacementNotice{Kind: ReplacementLesson, Teacher: "Петренко-Іваненко А.Б.", Title: "Фізичне виховання"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, err := ParseReplacement(tC.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tC.want {
+				t.Errorf("want: %+v, got: %+v", tC.want, got)
+			}
+		})
+	}
+}
+
+func TestParseReplacementErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"Увага!",
+		"Увага! Заміна!",
+		"Увага! Заміна! Горобець С.М. Графіка Л",
+		"Увага! Заміна! Графіка Л замість:",
+		"Перенесено на 17.10.2023",
+	} {
+		_, err := ParseReplacement(text)
+		if !errors.Is(err, ErrReplacementParse) {
+			t.Errorf("%q: want ErrReplacementParse, got: %v", text, err)
+		}
+	}
+}
+
+func TestConvertReplacement(t *testing.T) {
+	d := NewDirectory()
+	d.SetTeachers([]Teacher{{ShortName: "Горобець С.М.", Id: 420}})
+
+	got := convertReplacement(d, "Увага! Заміна! Горобець С.М. Графіка Л замість:")
+	if got.Teacher.Id != 420 || got.Kind != ReplacementLesson || got.Title != "Графіка" || got.Type != "Л" {
+		t.Errorf("unexpected replacement: %+v", got)
+	}
+
+	got = convertReplacement(d, "Заміна скасована")
+	if got.Kind != ReplacementUnknown || got.Text != "Заміна скасована" {
+		t.Errorf("unexpected replacement: %+v", got)
+	}
+}
@@ -0,0 +1,104 @@
+package psrozklad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reservation is a booking of a room that is not a regular lesson, e.g. a meeting or an exam of another faculty.
+type Reservation struct {
+	Room    Room
+	RoomRef Ref
+	Day     string
+	// Number is the number of the lesson slot, 0 if the API doesn't give one.
+	Number    int
+	StartTime time.Time
+	EndTime   time.Time
+	// Purpose is the text of the reservation field, e.g. "Засідання кафедри".
+	Purpose string
+	// Owner is the text of the API about who booked the room, e.g. a teacher or a department.
+	Owner string
+	// Raw is the item as the API returned it.
+	Raw json.RawMessage
+}
+
+// isReservation reports whether the item is a reservation of a room rather than a lesson.
+func (l lessonExport) isReservation() bool {
+	return strings.TrimSpace(l.Reservation) != ""
+}
+
+// convertLessonExportToReservation converts a lessonExport struct that is a reservation to a Reservation struct.
+func convertLessonExportToReservation(d *Directory, loc *time.Location, les lessonExport, t string) (Reservation, error) {
+	var err error
+
+	// In room mode the Object is the requested room.
+	if t == "room" {
+		les.Room = les.Object
+	}
+
+	res := Reservation{
+		Day:     les.Date,
+		Purpose: strings.TrimSpace(les.Reservation),
+		Raw:     les.raw,
+	}
+	var ok bool
+	res.Room, ok = d.Room(les.Room)
+	res.RoomRef = Ref{Raw: les.Room, Resolved: ok}
+
+	// The owner is whoever the API names: the teacher, the group or the comment.
+	// In group and teacher mode the requested object is the owner if nobody else is named.
+	owners := []string{les.Teacher, les.Group, les.Comment}
+	if t != "room" {
+		owners = append(owners, les.Object)
+	}
+	for _, owner := range owners {
+		if owner = strings.TrimSpace(owner); owner != "" {
+			res.Owner = owner
+			break
+		}
+	}
+
+	res.StartTime, res.EndTime, err = convertTime(les.Lesson_time, les.Date, loc)
+	if err != nil {
+		return Reservation{}, fmt.Errorf("failed to convert reservation time: %w", err)
+	}
+
+	// Reservations outside of the lesson slots have no number.
+	if les.Number != "" {
+		res.Number, err = strconv.Atoi(les.Number)
+		if err != nil {
+			return Reservation{}, fmt.Errorf("%w: failed to convert lesson number: %v", ErrMalformedPayload, err)
+		}
+	}
+
+	return res, nil
+}
+
+// GetTimetable gets the lessons and the reservations of any object for the given time period.
+// GetLessons returns the same lessons without the reservations.
+func (a *Api) GetTimetable(obj Object, start, end time.Time) ([]Lesson, []Reservation, error) {
+	return a.GetTimetableContext(context.Background(), obj, start, end)
+}
+
+// GetTimetableContext is like GetTimetable but uses ctx for the http request.
+func (a *Api) GetTimetableContext(ctx context.Context, obj Object, start, end time.Time) ([]Lesson, []Reservation, error) {
+	tt, err := a.getTimetable(ctx, obj, start, end)
+	if err != nil && !IsStale(err) {
+		return nil, nil, err
+	}
+	return tt.Lessons, tt.Reservations, err
+}
+
+// GetRoomTimetable gets the lessons and the reservations of the room for the given time period.
+func (a *Api) GetRoomTimetable(room Room, start, end time.Time) ([]Lesson, []Reservation, error) {
+	return a.GetTimetableContext(context.Background(), room, start, end)
+}
+
+// GetRoomTimetableContext is like GetRoomTimetable but uses ctx for the http request.
+func (a *Api) GetRoomTimetableContext(ctx context.Context, room Room, start, end time.Time) ([]Lesson, []Reservation, error) {
+	return a.GetTimetableContext(ctx, room, start, end)
+}
@@ -0,0 +1,88 @@
+package psrozklad
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetRoomTimetable(t *testing.T) {
+	body := `{"psrozklad_export": {"roz_items": [
+		{"object": "320/№1", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "teacher": "Горобець С.М.", "group": "21Бд-СОмат", "title": "Інженерна графіка", "type": "Л"},
+		{"object": "320/№1", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50", "teacher": "Горобець С.М.", "reservation": "Засідання кафедри"},
+		{"object": "320/№1", "date": "16.10.2023", "lesson_number": "", "lesson_time": "16:00-18:00", "comment": "Студрада", "reservation": " Збори "}
+	], "code": "0"}}`
+	room := Room{Name: "320", Block: "№1", FullName: "320/№1", Id: 36}
+	api := newTestApi(t, WithHTTPClient(&MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}), WithCache(NewCache(DefaultCacheConfig)))
+	api.Directory.SetRooms([]Room{room})
+
+	lessons, reservations, err := api.GetRoomTimetable(room, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].Title != "Інженерна графіка" {
+		t.Errorf("unexpected lessons: %+v", lessons)
+	}
+	for i := range reservations {
+		reservations[i].Raw = nil
+	}
+	want := []Reservation{
+		{
+			Room:      room,
+			RoomRef:   Ref{Raw: "320/№1", Resolved: true},
+			Day:       "16.10.2023",
+			Number:    2,
+			StartTime: time.Date(2023, time.October, 16, 10, 30, 0, 0, Kyiv),
+			EndTime:   time.Date(2023, time.October, 16, 11, 50, 0, 0, Kyiv),
+			Purpose:   "Засідання кафедри",
+			Owner:     "Горобець С.М.",
+		},
+		{
+			Room:      room,
+			RoomRef:   Ref{Raw: "320/№1", Resolved: true},
+			Day:       "16.10.2023",
+			StartTime: time.Date(2023, time.October, 16, 16, 0, 0, 0, Kyiv),
+			EndTime:   time.Date(2023, time.October, 16, 18, 0, 0, 0, Kyiv),
+			Purpose:   "Збори",
+			Owner:     "Студрада",
+		},
+	}
+	if !reflect.DeepEqual(reservations, want) {
+		t.Errorf("want: \n%v\ngot: \n%v", want, reservations)
+	}
+
+	// GetLessons shares the cached timetable and returns only the lessons.
+	lessons, err = api.GetLessons(room, time.Time{}, time.Time{})
+	if err != nil || len(lessons) != 1 {
+		t.Errorf("unexpected lessons: %+v, %v", lessons, err)
+	}
+	if stats := api.CacheStats(); stats.Hits != 1 {
+		t.Errorf("unexpected cache stats: %+v", stats)
+	}
+}
+
+func TestGetTimetableGroup(t *testing.T) {
+	body := `{"psrozklad_export": {"roz_items": [
+		{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20", "room": "320/№1", "title": "Інженерна графіка"},
+		{"object": "21Бд-СОмат", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50", "room": "320/№1", "reservation": "Збори групи"}
+	], "code": "0"}}`
+	room := Room{Name: "320", Block: "№1", FullName: "320/№1", Id: 36}
+	api := newTestApi(t, WithHTTPClient(&MockHttpClient{http.Response{Body: io.NopCloser(bytes.NewBufferString(body))}}), WithCache(NewCache(DefaultCacheConfig)))
+	api.Directory.SetRooms([]Room{room})
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+
+	// The reservation of a group query is reachable too.
+	lessons, reservations, err := api.GetTimetable(group, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lessons) != 1 || len(reservations) != 1 {
+		t.Fatalf("unexpected timetable: %+v, %+v", lessons, reservations)
+	}
+	if res := reservations[0]; res.Purpose != "Збори групи" || res.Room != room || res.Owner != "21Бд-СОмат" || res.Number != 2 {
+		t.Errorf("unexpected reservation: %+v", res)
+	}
+}
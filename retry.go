@@ -0,0 +1,159 @@
+package psrozklad
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how failed requests are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt, it doubles with every attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. A Retry-After longer than MaxDelay stops retrying.
+	MaxDelay time.Duration
+	// Jitter is the fraction (0..1) of the delay that is randomized.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a retry policy suitable for the timetable_export.cgi server.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.5,
+}
+
+// RetryClient is a Client that retries idempotent requests on network errors, 429 and 5xx responses.
+type RetryClient struct {
+	Client Client
+	Policy RetryPolicy
+}
+
+// NewRetryClient wraps c with retries according to p.
+func NewRetryClient(c Client, p RetryPolicy) *RetryClient {
+	return &RetryClient{Client: c, Policy: p}
+}
+
+// SetRetryPolicy wraps the HttpClient of the Api with retries according to p.
+func (a *Api) SetRetryPolicy(p RetryPolicy) {
+	// Replace the policy instead of wrapping a retry client twice.
+	if rc, ok := a.HttpClient.(*RetryClient); ok {
+		rc.Policy = p
+		return
+	}
+	a.HttpClient = NewRetryClient(a.HttpClient, p)
+}
+
+// Do executes the request, retrying it if needed.
+func (r *RetryClient) Do(req *http.Request) (*http.Response, error) {
+	// Only requests without a body that don't change anything can be repeated safely.
+	if !isIdempotent(req) {
+		return r.Client.Do(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		resp, err := r.Client.Do(req)
+		if attempt >= r.Policy.MaxAttempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+
+		// Compute the delay before the next attempt.
+		delay := r.Policy.backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				// Give up if the server asks to wait longer than allowed.
+				if r.Policy.MaxDelay > 0 && after > r.Policy.MaxDelay {
+					return resp, err
+				}
+				if after > delay {
+					delay = after
+				}
+			}
+
+			// Drain and close the body so the connection can be reused.
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		// Wait for the delay or for the request to be canceled.
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isIdempotent reports whether req can be repeated.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
+		return req.Body == nil || req.Body == http.NoBody
+	}
+	return false
+}
+
+// shouldRetry reports whether the result of an attempt is worth retrying.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Don't retry if the caller gave up.
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return true
+	}
+	return (&StatusError{StatusCode: resp.StatusCode}).Temporary()
+}
+
+// backoff returns the delay after the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	// Randomize part of the delay so that clients don't retry at the same time.
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// retryAfter parses the Retry-After header, either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,127 @@
+package psrozklad
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// seqHttpClient returns the given statuses one after another and counts the calls.
+type seqHttpClient struct {
+	statuses []int
+	headers  []http.Header
+	calls    int
+}
+
+func (s *seqHttpClient) Do(req *http.Request) (*http.Response, error) {
+	i := s.calls
+	if i >= len(s.statuses) {
+		i = len(s.statuses) - 1
+	}
+	s.calls++
+	resp := &http.Response{
+		StatusCode: s.statuses[i],
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"psrozklad_export": {"departments": [], "code": "0"}}`)),
+	}
+	if i < len(s.headers) && s.headers[i] != nil {
+		resp.Header = s.headers[i]
+	}
+	return resp, nil
+}
+
+func TestRetryClient(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond, Jitter: 0.5}
+
+	t.Run("retries until success", func(t *testing.T) {
+		seq := &seqHttpClient{statuses: []int{503, 502, 200}}
+		api := newTestApi(t)
+		api.HttpClient = seq
+		api.SetRetryPolicy(policy)
+		if _, err := api.GetGroups(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if seq.calls != 3 {
+			t.Errorf("want 3 calls, got %d", seq.calls)
+		}
+	})
+
+	t.Run("stops after max attempts", func(t *testing.T) {
+		seq := &seqHttpClient{statuses: []int{500}}
+		api := newTestApi(t)
+		api.HttpClient = seq
+		api.SetRetryPolicy(policy)
+		_, err := api.GetGroups()
+		var status *StatusError
+		if !errors.As(err, &status) || status.StatusCode != 500 {
+			t.Errorf("want *StatusError 500, got: %v", err)
+		}
+		if seq.calls != 3 {
+			t.Errorf("want 3 calls, got %d", seq.calls)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		seq := &seqHttpClient{statuses: []int{404, 200}}
+		api := newTestApi(t)
+		api.HttpClient = seq
+		api.SetRetryPolicy(policy)
+		if _, err := api.GetGroups(); err == nil {
+			t.Errorf("want error")
+		}
+		if seq.calls != 1 {
+			t.Errorf("want 1 call, got %d", seq.calls)
+		}
+	})
+
+	t.Run("gives up on long Retry-After", func(t *testing.T) {
+		seq := &seqHttpClient{statuses: []int{429, 200}, headers: []http.Header{{"Retry-After": []string{"120"}}}}
+		api := newTestApi(t)
+		api.HttpClient = seq
+		api.SetRetryPolicy(policy)
+		if _, err := api.GetGroups(); err == nil {
+			t.Errorf("want error")
+		}
+		if seq.calls != 1 {
+			t.Errorf("want 1 call, got %d", seq.calls)
+		}
+	})
+
+	t.Run("respects context", func(t *testing.T) {
+		seq := &seqHttpClient{statuses: []int{503}}
+		api := newTestApi(t)
+		api.HttpClient = seq
+		api.SetRetryPolicy(RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := api.GetGroupsContext(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want context.DeadlineExceeded, got: %v", err)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.October, 16, 9, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Mon, 16 Oct 2023 09:00:30 GMT", 30 * time.Second, true},
+		{"soon", 0, false},
+	}
+	for _, tC := range testCases {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{tC.value}}}
+		got, ok := retryAfter(resp, now)
+		if got != tC.want || ok != tC.ok {
+			t.Errorf("%q: want %v, %v, got %v, %v", tC.value, tC.want, tC.ok, got, ok)
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package psrozklad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"strconv"
 	"strings"
 )
@@ -24,78 +23,80 @@ func (r Room) ID() int {
 func (r Room) type_obj() string {
 	return "room"
 }
+
 // GetRooms gets list of all rooms.
 func (a *Api) GetRooms() ([]Room, error) {
-    // This function gets a list of rooms from the PS Rozklad API.
-
-    type roomExport struct {
-        Name string `json:"name"`
-        Id   string `json:"ID"`
-    }
-
-    type blockExport struct {
-        Name  string       `json:"name"`
-        Rooms []roomExport `json:"objects"`
-    }
-
-    type psrozkladExport struct {
-        Blocks []blockExport `json:"blocks"`
-        Code   string        `json:"code"`
-    }
-
-    type export struct {
-        PsrozkladExport psrozkladExport `json:"psrozklad_export"`
-    }
-
-    // Create a URL to the PS Rozklad API.
-    url := a.BaseUri
-    url += "&req_type=obj_list"
-    url += "&req_mode=room"
-    url += "&show_ID=yes"
-
-    // Create a new HTTP request.
-    req, err := http.NewRequest("GET", url, nil)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create http request: %v", err)
-    }
-
-    // Do the HTTP request.
-    resp, err := a.HttpClient.Do(req)
-    if err != nil {
-        return nil, fmt.Errorf("failed to do http request: %v", err)
-    }
-    defer resp.Body.Close()
-
-    // Decode the JSON response into an export struct.
-    var exp export
-    err = json.NewDecoder(resp.Body).Decode(&exp)
-    if err != nil {
-        return nil, fmt.Errorf("failed to decode room list json: %v", err)
-    }
-
-    // Create a list of Room structs.
-    var rooms []Room
-    for _, block := range exp.PsrozkladExport.Blocks {
-        for _, room := range block.Rooms {
-            // Convert the ID string to an integer.
-            id, err := strconv.Atoi(room.Id)
-            if err != nil {
-                return nil, fmt.Errorf("failed to convert id: %v, to string: %v", room.Id, err)
-            }
-
-            // Split the room name into two parts: block and name.
-            n := strings.Split(room.Name, "/")
-
-            // Add the room to the list of rooms.
-            rooms = append(rooms, Room{
-                Block:    block.Name,
-                Name:     n[0],
-                FullName: room.Name,
-                Id:       id,
-            })
-        }
-    }
-
-    // Return the list of rooms.
-    return rooms, nil
+	return a.GetRoomsContext(context.Background())
+}
+
+// GetRoomsContext is like GetRooms but uses ctx for the http request.
+func (a *Api) GetRoomsContext(ctx context.Context) ([]Room, error) {
+	// This function gets a list of rooms from the PS Rozklad API.
+
+	type roomExport struct {
+		Name string `json:"name"`
+		Id   string `json:"ID"`
+	}
+
+	type blockExport struct {
+		Name  string       `json:"name"`
+		Rooms []roomExport `json:"objects"`
+	}
+
+	type psrozkladExport struct {
+		Blocks []blockExport `json:"blocks"`
+		Code   string        `json:"code"`
+	}
+
+	type export struct {
+		PsrozkladExport psrozkladExport `json:"psrozklad_export"`
+	}
+
+	// Return the cached list if there is one.
+	key := listKey("room")
+	if cached, ok := a.Cache.get(key); ok {
+		return append([]Room(nil), cached.([]Room)...), nil
+	}
+
+	// Create a URL to the PS Rozklad API.
+	url := a.listURL("room")
+
+	// Do the HTTP request and decode the JSON response into an export struct.
+	var exp export
+	err := a.getJSON(ctx, url, &exp)
+	stale := staleError(err)
+	if err != nil && stale == nil {
+		return nil, fmt.Errorf("failed to get room list: %w", err)
+	}
+
+	// Create a list of Room structs.
+	var rooms []Room
+	for _, block := range exp.PsrozkladExport.Blocks {
+		for _, room := range block.Rooms {
+			// Convert the ID string to an integer.
+			id, err := strconv.Atoi(room.Id)
+			if err != nil {
+				return nil, fmt.Errorf("%w: failed to convert room id: %v, to int: %v", ErrMalformedPayload, room.Id, err)
+			}
+
+			// Split the room name into two parts: block and name.
+			n := strings.Split(room.Name, "/")
+
+			// Add the room to the list of rooms.
+			rooms = append(rooms, Room{
+				Block:    block.Name,
+				Name:     n[0],
+				FullName: room.Name,
+				Id:       id,
+			})
+		}
+	}
+
+	// Store a copy of the list in the cache, unless it is stale.
+	if stale == nil {
+		a.Cache.set(key, append([]Room(nil), rooms...))
+	}
+
+	// Return the list of rooms.
+	return rooms, stale
 }
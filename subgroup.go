@@ -0,0 +1,19 @@
+package psrozklad
+
+// ForSubgroup reports whether a student of subgroup n attends the lesson.
+// Common lessons are attended by every subgroup.
+func (l Lesson) ForSubgroup(n int) bool {
+	return l.SubgroupNumber == 0 || l.SubgroupNumber == n
+}
+
+// FilterForSubgroup returns the lessons attended by a student of subgroup n:
+// the common lessons and the lessons of subgroup n. If n is 0, only the common lessons are kept.
+func FilterForSubgroup(lessons []Lesson, n int) []Lesson {
+	var filtered []Lesson
+	for _, lesson := range lessons {
+		if lesson.ForSubgroup(n) {
+			filtered = append(filtered, lesson)
+		}
+	}
+	return filtered
+}
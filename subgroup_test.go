@@ -0,0 +1,33 @@
+package psrozklad
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterForSubgroup(t *testing.T) {
+	lessons := []Lesson{
+		{Title: "Алгебра", Number: 1},
+		{Title: "Мережі", Number: 2, SubgroupNumber: 1},
+		{Title: "Бази даних", Number: 2, SubgroupNumber: 2},
+		{Title: "Фізкультура", Number: 3},
+	}
+
+	testCases := []struct {
+		n    int
+		want []string
+	}{
+		{1, []string{"Алгебра", "Мережі", "Фізкультура"}},
+		{2, []string{"Алгебра", "Бази даних", "Фізкультура"}},
+		{0, []string{"Алгебра", "Фізкультура"}},
+	}
+	for _, tC := range testCases {
+		var got []string
+		for _, lesson := range FilterForSubgroup(lessons, tC.n) {
+			got = append(got, lesson.Title)
+		}
+		if !reflect.DeepEqual(got, tC.want) {
+			t.Errorf("subgroup %d: want: %v, got: %v", tC.n, tC.want, got)
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package psrozklad
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"strconv"
 )
 
@@ -28,6 +27,11 @@ func (t Teacher) type_obj() string {
 
 // GetTeachers returns a list of teachers from the API.
 func (a *Api) GetTeachers() ([]Teacher, error) {
+	return a.GetTeachersContext(context.Background())
+}
+
+// GetTeachersContext is like GetTeachers but uses ctx for the http request.
+func (a *Api) GetTeachersContext(ctx context.Context) ([]Teacher, error) {
 	// Define a struct to represent a teacher export from the API.
 	type teacherExport struct {
 		Name string `json:"name"`
@@ -54,30 +58,21 @@ func (a *Api) GetTeachers() ([]Teacher, error) {
 		PsrozkladExport psrozkladExport `json:"psrozklad_export"`
 	}
 
-	// Construct the API request URL.
-	url := a.BaseUri
-	url += "&req_type=obj_list"
-	url += "&req_mode=teacher"
-	url += "&show_ID=yes"
-
-	// Create a new HTTP request.
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create http request: %v", err)
+	// Return the cached list if there is one.
+	key := listKey("teacher")
+	if cached, ok := a.Cache.get(key); ok {
+		return append([]Teacher(nil), cached.([]Teacher)...), nil
 	}
 
-	// Execute the HTTP request.
-	resp, err := a.HttpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to do http request: %v", err)
-	}
-	defer resp.Body.Close()
+	// Construct the API request URL.
+	url := a.listURL("teacher")
 
-	// Decode the JSON response into an export struct.
+	// Do the HTTP request and decode the JSON response into an export struct.
 	var exp export
-	err = json.NewDecoder(resp.Body).Decode(&exp)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode teachers list json: %v", err)
+	err := a.getJSON(ctx, url, &exp)
+	stale := staleError(err)
+	if err != nil && stale == nil {
+		return nil, fmt.Errorf("failed to get teacher list: %w", err)
 	}
 
 	// Create a slice of Teacher objects to store the results.
@@ -89,7 +84,7 @@ func (a *Api) GetTeachers() ([]Teacher, error) {
 			// Convert the teacher ID to an integer.
 			id, err := strconv.Atoi(teacher.Id)
 			if err != nil {
-				return nil, fmt.Errorf("failed to convert id: %v, to string: %v", teacher.Id, err)
+				return nil, fmt.Errorf("%w: failed to convert teacher id: %v, to int: %v", ErrMalformedPayload, teacher.Id, err)
 			}
 
 			// Create a new Teacher object and append it to the slice.
@@ -104,6 +99,11 @@ func (a *Api) GetTeachers() ([]Teacher, error) {
 		}
 	}
 
+	// Store a copy of the list in the cache, unless it is stale.
+	if stale == nil {
+		a.Cache.set(key, append([]Teacher(nil), teachers...))
+	}
+
 	// Return the slice of Teacher objects.
-	return teachers, nil
+	return teachers, stale
 }
@@ -8,49 +8,81 @@ import (
 )
 
 // convertLessonExportToLesson converts a lessonExport struct to a Lesson struct and returns it along with an error.
-// It takes the lessonExport and a type as input parameters.
-func convertLessonExportToLesson(les lessonExport, t string) (Lesson, error) {
+// It takes the directory to resolve against, the timezone, the logger of the problems that don't fail the lesson,
+// the lessonExport and a type as input parameters.
+func convertLessonExportToLesson(d *Directory, loc *time.Location, logf func(format string, v ...interface{}), les lessonExport, t string) (Lesson, error) {
 	// Initialize the error variable.
 	var err error
 
+	// coteacher is true unless the requested teacher is the main teacher of the lesson.
+	coteacher := true
+
 	// Depending on the value of type, update the relevant fields of the `les` struct.
 	switch t {
 	case "room":
 		// If `t` is "room," set the Room field in `les` to the Object field.
 		les.Room = les.Object
 	case "teacher":
-		// If `t` is "teacher," split the Object into parts, format it as a teacher's name, and assign it to the Teacher field in `les`.
-		teacher_list := strings.Split(les.Object, " ")
-		les.Teacher = teacher_list[0] + " " + string(teacher_list[1][:2]) + "." + string(teacher_list[2][:2]) + "."
+		// If `t` is "teacher," parse the full name in Object and assign its short name to the Teacher field in `les`.
+		name, err := ParseFullName(les.Object)
+		if err != nil {
+			// Keep the lesson, the requested teacher just stays unresolved.
+			logf("lesson %s %s: teacher %q: %v", les.Date, les.Number, les.Object, err)
+			if les.Teacher == "" {
+				coteacher = false
+			}
+			break
+		}
+		// A co-teacher gets the lesson with the main teacher in the Teacher field,
+		// then the requested teacher is one of the additional teachers.
+		if les.Teacher != "" && !sameName(les.Teacher, name.ShortName()) {
+			if !containsName(splitTeachers(les.TeachersAdd), name.ShortName()) {
+				les.TeachersAdd = strings.TrimPrefix(les.TeachersAdd+", "+name.ShortName(), ", ")
+			}
+			break
+		}
+		les.Teacher = name.ShortName()
+		coteacher = false
 	}
 
 	// Create a new Lesson struct and initialize it with some fields from the `les` struct.
 	less_new := Lesson{
-		Title:   les.Title,
-		Teacher: Teachers[strings.ToLower(les.Teacher)],
-		Type:    les.Type,
+		Title:      les.Title,
+		Type:       les.Type,
+		LessonType: ParseLessonType(les.Type),
+	}
+	var ok bool
+	less_new.Teacher, ok = d.Teacher(les.Teacher)
+	less_new.TeacherRef = Ref{Raw: les.Teacher, Resolved: ok}
+	if t == "teacher" && !coteacher {
+		// Keep the full name the API wrote, not the short name made from it.
+		less_new.TeacherRef.Raw = les.Object
 	}
-	less_new.Room = Rooms[strings.ToLower(les.Room)]
+	less_new.Room, ok = d.Room(les.Room)
+	less_new.RoomRef = Ref{Raw: les.Room, Resolved: ok}
 
 	// Use the convertTime function to parse lesson time and date, and assign the result to the StartTime and EndTime fields in `less_new`.
-	less_new.StartTime, less_new.EndTime, err = convertTime(les.Lesson_time, les.Date)
+	less_new.StartTime, less_new.EndTime, err = convertTime(les.Lesson_time, les.Date, loc)
 	if err != nil {
-		return Lesson{}, fmt.Errorf("failed to convert lesson time: %v", err)
+		return Lesson{}, fmt.Errorf("failed to convert lesson time: %w", err)
 	}
 
-	// If `t` is not "group" and the Group field in `les` ends with a closing parenthesis, split the Group field to populate the Groups, GroupsType, and SubGroup fields.
-	if t != "group" && strings.HasSuffix(les.Group, ")") {
-		splited_groupe := strings.Split(les.Group, " ")
-		less_new.Groups, less_new.GroupsType, less_new.SubGroup = convertStringToGroupes(splited_groupe[0], splited_groupe[1]+" "+splited_groupe[2])
-	} else {
-		// Otherwise, use the Object and Group fields to populate the Groups, GroupsType, and SubGroup fields.
-		less_new.Groups, less_new.GroupsType, less_new.SubGroup = convertStringToGroupes(les.Object, les.Group)
+	// Parse the Group field to find out who attends the lesson. In group mode the Object is the requested group.
+	obj := ""
+	if t == "group" {
+		obj = les.Object
 	}
+	less_new.Assignment = ParseGroupAssignment(obj, les.Group)
+	less_new.GroupRefs = less_new.Assignment.resolve(d)
+	less_new.Groups = less_new.Assignment.Groups
+	less_new.GroupsType = less_new.Assignment.Kind.groupsType()
+	less_new.SubGroup = less_new.Assignment.Label
+	less_new.SubgroupNumber = less_new.Assignment.Subgroup
 
 	// Convert the Number field in `les` to an integer and assign it to the Number field in `less_new`.
 	less_new.Number, err = strconv.Atoi(les.Number)
 	if err != nil {
-		return Lesson{}, err
+		return Lesson{}, fmt.Errorf("%w: failed to convert lesson number: %v", ErrMalformedPayload, err)
 	}
 
 	// Set the Day field in `less_new` to the Date field in `les`.
@@ -63,53 +95,90 @@ func convertLessonExportToLesson(les lessonExport, t string) (Lesson, error) {
 		less_new.CommentForLink = les.Comment4link
 	}
 
+	// Copy the fields that need no conversion.
+	less_new.Comment = les.Comment
+	less_new.LessonName = les.LessonName
+	less_new.Half = parseHalf(les.Half)
+	less_new.Raw = les.raw
+
+	// Resolve the co-teachers listed in teachers_add, the unresolved ones are only in the refs.
+	for _, name := range splitTeachers(les.TeachersAdd) {
+		teacher, ok := d.Teacher(name)
+		if ok {
+			less_new.AdditionalTeachers = append(less_new.AdditionalTeachers, teacher)
+		}
+		less_new.AdditionalTeacherRefs = append(less_new.AdditionalTeacherRefs, Ref{Raw: name, Resolved: ok})
+	}
+
+	// Teachers lists the resolved teachers of the lesson, the main teacher first.
+	if less_new.TeacherRef.Resolved {
+		less_new.Teachers = append(less_new.Teachers, less_new.Teacher)
+	}
+	less_new.Teachers = append(less_new.Teachers, less_new.AdditionalTeachers...)
+
 	// If there is a Replacement field in `les`, handle it by Replacement field in `less_new`.
 	if les.Replacement != "" {
-		less_new.Replacement.Teacher, less_new.Replacement.Title, less_new.Replacement.Type = convertReplacment(les.Replacement)
+		less_new.Replacement = convertReplacement(d, les.Replacement)
 	}
 
 	// Return the converted Lesson struct and a nil error.
 	return less_new, nil
 }
 
-// convertReplacment converts a replacement string to a Teacher struct, a title string, and a lesson type string.
-func convertReplacment(replacment string) (Teacher Teacher, title string, leson_typ string) {
-	// If the replacement string ends with "замість:", then it is a replacement for a teacher and a lesson.
-	if strings.HasSuffix(replacment, "замість:") {
-		// Get rid of the "Увага! Заміна! " and " замість:" prefixes.
-		replacment = replacment[26 : len(replacment)-16]
-
-		// Split the replacement string into a slice of strings.
-		replacmentSlice := strings.Split(replacment, " ")
-
-		// The first two strings in the slice are the teacher's name.
-		teacher := replacmentSlice[0]
-		teacher = strings.ReplaceAll(teacher, " ", " ")
-		Teacher = Teachers[teacher]
+// splitTeachers splits a list of teachers like "Горобець С.М., Яценко О.С." into short names.
+func splitTeachers(s string) []string {
+	var names []string
+	for _, name := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		// Non-breaking spaces are used between the surname and the initials.
+		name = strings.Join(strings.Fields(name), " ")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
 
-		// The last string in the slice is the lesson type.
-		leson_typ = replacmentSlice[len(replacmentSlice)-1]
+// sameName reports whether two short names are the same, ignoring case and the kind of spaces.
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
 
-		// Concatenate the remaining strings in the slice to form the title.
-		for i := 1; i < len(replacmentSlice)-2; i++ {
-			title += replacmentSlice[i] + " "
+// sameNames reports whether a and b have the same names in any order.
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, name := range a {
+		if !containsName(b, name) {
+			return false
+		}
+	}
+	for _, name := range b {
+		if !containsName(a, name) {
+			return false
 		}
-		title += replacmentSlice[len(replacmentSlice)-2]
-	} else {
-		// If the replacement string does not end with "замість:", then it is a replacement for a teacher only.
-		replacmentSlice := strings.Split(replacment, ": ")
+	}
+	return true
+}
 
-		teacher := replacmentSlice[0][26 : len(replacmentSlice[0])-15]
-		teacher = strings.ReplaceAll(teacher, " ", " ")
-		Teacher = Teachers[teacher]
+// containsName reports whether names contains name.
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if sameName(n, name) {
+			return true
+		}
 	}
+	return false
+}
 
-	// Return the Teacher struct, the title string, and the lesson type string.
-	return Teacher, title, leson_typ
+// formatDate formats the date of t as the API expects it, e.g. "16.10.2023" for 16 October 2023.
+func formatDate(t time.Time) string {
+	year, month, day := t.Date()
+	return strconv.Itoa(day) + "." + strconv.Itoa(int(month)) + "." + strconv.Itoa(year)
 }
 
-// Convert a string representing a date and time to two time.Time objects.
-func convertTime(t, d string) (time.Time, time.Time, error) {
+// Convert a string representing a date and time to two time.Time objects in the location loc.
+func convertTime(t, d string, loc *time.Location) (time.Time, time.Time, error) {
 	// Split the date string into three parts: year, month, and day.
 	dataa := strings.Split(d, ".")
 
@@ -122,84 +191,49 @@ func convertTime(t, d string) (time.Time, time.Time, error) {
 		tt = append(tt, strings.Split(v, ":"))
 	}
 
+	// Check that the date and both times have all of their parts.
+	if len(dataa) != 3 || len(tt) != 2 || len(tt[0]) != 2 || len(tt[1]) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q", ErrDateParse, d, t)
+	}
+
 	// Convert the year, month, day, start hour, start minute, end hour, and end minute to integers.
 	year, err := strconv.Atoi(dataa[2])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	month, err := strconv.Atoi(dataa[1])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	day, err := strconv.Atoi(dataa[0])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	start_h, err := strconv.Atoi(tt[0][0])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	start_m, err := strconv.Atoi(tt[0][1])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	end_h, err := strconv.Atoi(tt[1][0])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 	end_m, err := strconv.Atoi(tt[1][1])
 	if err != nil {
-		return time.Time{}, time.Time{}, err
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q %q: %w", ErrDateParse, d, t, err)
 	}
 
 	// Create two time.Time objects for the start and end times.
-	start := time.Date(year, time.Month(month), day, start_h, start_m, 0, 0, time.Local)
-	end := time.Date(year, time.Month(month), day, end_h, end_m, 0, 0, time.Local)
+	start := time.Date(year, time.Month(month), day, start_h, start_m, 0, 0, loc)
+	end := time.Date(year, time.Month(month), day, end_h, end_m, 0, 0, loc)
 
 	// Return the start and end time.Time objects.
 	return start, end, nil
 }
 
-// convertStringToGroupes converts a string representation of a group to a slice of Group
-// objects and a string representing the group type.
-func convertStringToGroupes(obj, groups string) ([]Group, string, string) {
-	var gr_l []Group
-	var gr_t, subGr string
-
-	// If the groups string is empty, then the group is the same as the obj string.
-	if groups == "" {
-		gr_l = append(gr_l, Groups[strings.ToLower(obj)])
-	} else if strings.HasPrefix(groups, "(") {
-		// If the groups string starts with a parenthesis, then it is a subgroup.
-		gr_l = append(gr_l, Groups[strings.ToLower(obj)])
-		subGr = groups
-		gr_t = "підгр"
-	} else if strings.HasPrefix(groups, "Збірна група") {
-		// If the groups string starts with "Збірна група", then it is a combined group.
-		s := groups[24:]
-		subGr = s
-		groups := strings.Split(s, ", ")
-		for _, gr := range groups {
-			gr_l = append(gr_l, Groups[strings.ToLower(gr)])
-		}
-		gr_t = "Збірна група"
-	} else if strings.HasPrefix(groups, "Потік") {
-		// If the groups string starts with "Потік", then it is a stream.
-		s := groups[11:]
-		subGr = s
-		groups := strings.Split(s, ", ")
-		for _, gr := range groups {
-			gr_l = append(gr_l, Groups[strings.ToLower(gr)])
-		}
-		gr_t = "Потік"
-	} else {
-		// Otherwise, the group is the same as the groups string.
-		gr_l = append(gr_l, Groups[strings.ToLower(groups)])
-	}
-
-	return gr_l, gr_t, subGr
-}
-
 // convertLessons converts all lessons with a GroupsType of "підгр" to have a GroupsType of "підгр".
 func convertLessons(lessons []Lesson) []Lesson {
 	// ifSubGroupe is a flag that indicates whether any of the lessons have a GroupsType of "підгр".
@@ -0,0 +1,38 @@
+package psrozklad
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertTimeKyiv(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		date     string
+		time     string
+		offset   int
+		duration time.Duration
+	}{
+		{"winter", "16.01.2023", "09:00-10:20", 2 * 60 * 60, 80 * time.Minute},
+		{"summer", "16.10.2023", "09:00-10:20", 3 * 60 * 60, 80 * time.Minute},
+		{"spring forward", "26.03.2023", "09:00-10:20", 3 * 60 * 60, 80 * time.Minute},
+		{"fall back", "29.10.2023", "09:00-10:20", 2 * 60 * 60, 80 * time.Minute},
+		// The clocks go from 03:00 to 04:00, so only an hour passes.
+		{"across spring forward", "26.03.2023", "02:30-04:30", 3 * 60 * 60, time.Hour},
+	}
+	for _, tC := range testCases {
+		start, end, err := convertTime(tC.time, tC.date, Kyiv)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tC.desc, err)
+		}
+		if _, offset := end.Zone(); offset != tC.offset {
+			t.Errorf("%s: want offset %d, got %d", tC.desc, tC.offset, offset)
+		}
+		if d := end.Sub(start); d != tC.duration {
+			t.Errorf("%s: want duration %v, got %v", tC.desc, tC.duration, d)
+		}
+		if start.Location() != Kyiv {
+			t.Errorf("%s: want Kyiv location, got %v", tC.desc, start.Location())
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package psrozklad
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// WatchEvent reports the changes of the timetable of a watched object.
+type WatchEvent struct {
+	Object Object
+	// Changes are the changes since the previous snapshot.
+	Changes []Change
+	// Err is the error of the poll, the Changes are empty then,
+	// or the error of persisting the snapshot, the Changes are still reported then.
+	Err error
+	// At is the time of the poll.
+	At time.Time
+}
+
+// WatcherConfig configures a Watcher.
+type WatcherConfig struct {
+	// Interval is the time between two polls, 15 minutes if zero.
+	Interval time.Duration
+	// Jitter is the maximum random time added to every Interval, so that many watchers don't poll at once.
+	Jitter time.Duration
+	// Days is the length of the rolling window starting today, 14 if zero.
+	Days int
+	// SnapshotDir, if not empty, is the directory where the last snapshots are kept across restarts.
+	SnapshotDir string
+	// OnEvent, if not nil, is called with every event instead of sending it to the Events channel.
+	OnEvent func(WatchEvent)
+	// Buffer is the capacity of the Events channel.
+	Buffer int
+}
+
+// Watcher polls the timetables of the watched objects and reports their changes.
+// The polls ask the API and not the caches of the Api, but still store the timetables in them.
+type Watcher struct {
+	api    *Api
+	cfg    WatcherConfig
+	events chan WatchEvent
+
+	mu        sync.Mutex
+	objects   map[string]Object
+	snapshots map[string]snapshot
+	started   bool
+
+	// done is closed when Run returns, so the blocked sends give up before the events channel is closed.
+	done chan struct{}
+	// sendMu is held for reading by the sends and for writing to close the events channel.
+	sendMu sync.RWMutex
+	closed bool
+}
+
+// snapshot is the timetable of an object over a window, as it is persisted.
+type snapshot struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Lessons []Lesson  `json:"lessons"`
+}
+
+// NewWatcher creates a Watcher that uses the Api to fetch the timetables.
+func NewWatcher(a *Api, cfg WatcherConfig) (*Watcher, error) {
+	if cfg.Interval == 0 {
+		cfg.Interval = 15 * time.Minute
+	}
+	if cfg.Days == 0 {
+		cfg.Days = 14
+	}
+	if cfg.Interval < 0 || cfg.Jitter < 0 || cfg.Days < 0 || cfg.Buffer < 0 {
+		return nil, errors.New("negative watcher config")
+	}
+	if cfg.SnapshotDir != "" {
+		err := os.MkdirAll(cfg.SnapshotDir, 0o755)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create snapshot dir: %w", err)
+		}
+	}
+	return &Watcher{
+		api:       a,
+		cfg:       cfg,
+		events:    make(chan WatchEvent, cfg.Buffer),
+		done:      make(chan struct{}),
+		objects:   make(map[string]Object),
+		snapshots: make(map[string]snapshot),
+	}, nil
+}
+
+// watchKey returns the key of the object, e.g. "group-11".
+func watchKey(obj Object) string {
+	return obj.type_obj() + "-" + strconv.Itoa(obj.ID())
+}
+
+// Watch adds the object to the watched objects.
+func (w *Watcher) Watch(obj Object) error {
+	if obj == nil {
+		return fmt.Errorf("%w: nil object", ErrUnknownObject)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.objects[watchKey(obj)] = obj
+	return nil
+}
+
+// Unwatch removes the object from the watched objects and forgets its snapshot.
+func (w *Watcher) Unwatch(obj Object) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key := watchKey(obj)
+	delete(w.objects, key)
+	delete(w.snapshots, key)
+	if w.cfg.SnapshotDir != "" {
+		os.Remove(w.snapshotPath(key))
+	}
+}
+
+// Events returns the channel of the events. It is closed when Run returns.
+func (w *Watcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Run polls until ctx is done, the first time right away. It returns the error of ctx.
+// A Watcher runs only once, a second Run returns ErrWatcherRunning.
+func (w *Watcher) Run(ctx context.Context) error {
+	w.mu.Lock()
+	started := w.started
+	w.started = true
+	w.mu.Unlock()
+	if started {
+		return ErrWatcherRunning
+	}
+	defer w.closeEvents()
+	for {
+		w.Poll(ctx)
+
+		wait := w.cfg.Interval
+		if w.cfg.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(w.cfg.Jitter)))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll fetches the timetables of all watched objects once and delivers the events.
+// The first poll of an object only takes its snapshot, unless one was persisted before.
+// After Run returns, the events are only delivered to OnEvent.
+func (w *Watcher) Poll(ctx context.Context) {
+	w.mu.Lock()
+	objects := make(map[string]Object, len(w.objects))
+	for key, obj := range w.objects {
+		objects[key] = obj
+	}
+	w.mu.Unlock()
+
+	for key, obj := range objects {
+		if ctx.Err() != nil {
+			return
+		}
+		if event, ok := w.poll(ctx, key, obj); ok {
+			w.deliver(ctx, event)
+		}
+	}
+}
+
+// poll fetches the timetable of one object and diffs it with the previous snapshot.
+// It reports whether there is an event.
+func (w *Watcher) poll(ctx context.Context, key string, obj Object) (WatchEvent, bool) {
+	now := time.Now()
+	event := WatchEvent{Object: obj, At: now}
+
+	// The window starts today.
+	from := atClock(now.In(w.api.location()), 0)
+	to := from.AddDate(0, 0, w.cfg.Days)
+
+	// Ask the API, not the caches.
+	tt, err := w.api.loadTimetable(ctx, obj, from, to, false)
+	if err != nil {
+		// Stale lessons are not a new snapshot.
+		event.Err = err
+		return event, true
+	}
+	current := snapshot{From: from, To: to, Lessons: tt.Lessons}
+
+	prev, ok := w.snapshot(key)
+	// A snapshot that is not persisted is still reported, it would be lost on restart.
+	event.Err = w.setSnapshot(key, current)
+	if !ok {
+		return event, event.Err != nil
+	}
+
+	// Compare the days in both windows only, the days entering and leaving the window are not changes.
+	event.Changes = Diff(prev.between(current.From, prev.To), current.between(current.From, prev.To))
+	return event, len(event.Changes) > 0 || event.Err != nil
+}
+
+// between returns the lessons of the snapshot that start between from and to.
+func (s snapshot) between(from, to time.Time) []Lesson {
+	var lessons []Lesson
+	for _, lesson := range s.Lessons {
+		if !lesson.StartTime.Before(from) && lesson.StartTime.Before(to) {
+			lessons = append(lessons, lesson)
+		}
+	}
+	return lessons
+}
+
+// deliver calls OnEvent or sends the event to the Events channel.
+func (w *Watcher) deliver(ctx context.Context, event WatchEvent) {
+	if w.cfg.OnEvent != nil {
+		w.cfg.OnEvent(event)
+		return
+	}
+	w.sendMu.RLock()
+	defer w.sendMu.RUnlock()
+	if w.closed {
+		return
+	}
+	select {
+	case w.events <- event:
+	case <-ctx.Done():
+	case <-w.done:
+	}
+}
+
+// closeEvents closes the events channel once no event is being sent.
+func (w *Watcher) closeEvents() {
+	close(w.done)
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	w.closed = true
+	close(w.events)
+}
+
+// snapshotPath returns the path of the persisted snapshot of the object with the key.
+func (w *Watcher) snapshotPath(key string) string {
+	return filepath.Join(w.cfg.SnapshotDir, key+".json")
+}
+
+// snapshot returns the last snapshot of the object with the key, loading it from the SnapshotDir if needed.
+func (w *Watcher) snapshot(key string) (snapshot, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if s, ok := w.snapshots[key]; ok {
+		return s, true
+	}
+	if w.cfg.SnapshotDir == "" {
+		return snapshot{}, false
+	}
+	data, err := os.ReadFile(w.snapshotPath(key))
+	if err != nil {
+		return snapshot{}, false
+	}
+	var s snapshot
+	// A broken snapshot is taken again.
+	if json.Unmarshal(data, &s) != nil {
+		return snapshot{}, false
+	}
+	return s, true
+}
+
+// setSnapshot stores the snapshot of the object with the key, persisting it if the SnapshotDir is set.
+// It returns the error of a failed write, the snapshot is still kept in memory then.
+func (w *Watcher) setSnapshot(key string, s snapshot) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// The object may have been unwatched during the poll.
+	if _, ok := w.objects[key]; !ok {
+		return nil
+	}
+	w.snapshots[key] = s
+	if w.cfg.SnapshotDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	err = writeFileAtomic(w.snapshotPath(key), data)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package psrozklad
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// bodyHttpClient returns the current body to every request.
+type bodyHttpClient struct {
+	mu   sync.Mutex
+	body string
+}
+
+func (b *bodyHttpClient) set(body string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.body = body
+}
+
+func (b *bodyHttpClient) Do(req *http.Request) (*http.Response, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewBufferString(b.body))}, nil
+}
+
+func TestWatcher(t *testing.T) {
+	tomorrow := time.Now().In(Kyiv).AddDate(0, 0, 1).Format("02.01.2006")
+	timetable := func(number, times string) string {
+		return `{"psrozklad_export": {"roz_items": [{"object": "21Бд-СОмат", "date": "` + tomorrow + `", "lesson_number": "` + number + `",
+			"lesson_time": "` + times + `", "title": "Мережі", "type": "Лаб", "room": "320/№1"}], "code": "0"}}`
+	}
+	client := &bodyHttpClient{body: timetable("1", "09:00-10:20")}
+	api := newTestApi(t, WithHTTPClient(client), WithCache(NewCache(DefaultCacheConfig)))
+	group := Group{Name: "21Бд-СОмат", Id: 11}
+	dir := t.TempDir()
+
+	var events []WatchEvent
+	w, err := NewWatcher(api, WatcherConfig{Days: 7, SnapshotDir: dir, OnEvent: func(e WatchEvent) { events = append(events, e) }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Watch(group); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The first poll only takes the snapshot, the second one sees no changes.
+	w.Poll(context.Background())
+	w.Poll(context.Background())
+	if len(events) != 0 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	// The moved lesson is reported, although the lessons are in the memory cache.
+	from := atClock(time.Now().In(Kyiv), 0)
+	cached, err := api.GetLessons(group, from, from.AddDate(0, 0, 7))
+	if err != nil || len(cached) != 1 {
+		t.Fatalf("unexpected lessons: %v, %v", cached, err)
+	}
+	client.set(timetable("2", "10:30-11:50"))
+	w.Poll(context.Background())
+	if len(events) != 1 || len(events[0].Changes) != 1 || events[0].Changes[0].Kind != ChangeMoved || events[0].Object != group {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	// The poll updated the memory cache instead of evicting it.
+	if lessons, err := api.GetLessons(group, from, from.AddDate(0, 0, 7)); err != nil || len(lessons) != 1 || lessons[0].Number != 2 {
+		t.Errorf("unexpected lessons: %v, %v", lessons, err)
+	}
+	// A failed poll doesn't evict the lessons of the other callers either.
+	client.set("not json")
+	w.Poll(context.Background())
+	if len(events) != 2 || events[1].Err == nil {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+	if lessons, err := api.GetLessons(group, from, from.AddDate(0, 0, 7)); err != nil || len(lessons) != 1 || lessons[0].Number != 2 {
+		t.Errorf("unexpected lessons: %v, %v", lessons, err)
+	}
+
+	// A new watcher continues from the persisted snapshot and delivers to the channel.
+	client.set(timetable("3", "12:20-13:40"))
+	w, err = NewWatcher(api, WatcherConfig{Days: 7, SnapshotDir: dir, Interval: time.Hour, Buffer: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Watch(group)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	select {
+	case e := <-w.Events():
+		if len(e.Changes) != 1 || e.Changes[0].Old.Number != 2 || e.Changes[0].New.Number != 3 {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Error("events channel is not closed")
+	}
+}
+
+func TestWatcherRunOnce(t *testing.T) {
+	// Every poll is an error event.
+	client := &bodyHttpClient{body: "not json"}
+	api := newTestApi(t, WithHTTPClient(client))
+	w, err := NewWatcher(api, WatcherConfig{Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Watch(Group{Name: "21Бд-СОмат", Id: 11})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	if e := <-w.Events(); e.Err == nil {
+		t.Errorf("unexpected event: %+v", e)
+	}
+
+	// A Poll blocked on the unbuffered channel gives up when Run returns.
+	polled := make(chan struct{})
+	go func() {
+		w.Poll(context.Background())
+		close(polled)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+	select {
+	case <-polled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("poll is blocked")
+	}
+
+	// Polls after Run don't send on the closed channel, and the Watcher doesn't run again.
+	w.Poll(context.Background())
+	if err := w.Run(context.Background()); err != ErrWatcherRunning {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWatcherSnapshotError(t *testing.T) {
+	client := &bodyHttpClient{body: `{"psrozklad_export": {"roz_items": [], "code": "0"}}`}
+	api := newTestApi(t, WithHTTPClient(client))
+	dir := t.TempDir()
+	var events []WatchEvent
+	w, err := NewWatcher(api, WatcherConfig{SnapshotDir: dir, OnEvent: func(e WatchEvent) { events = append(events, e) }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.Watch(Group{Name: "21Бд-СОмат", Id: 11})
+
+	// The snapshot can't be written to a removed directory.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	w.Poll(context.Background())
+	if len(events) != 1 || events[0].Err == nil || len(events[0].Changes) != 0 {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
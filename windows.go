@@ -0,0 +1,209 @@
+package psrozklad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Window is a period of time when everybody asked about is free.
+type Window struct {
+	Start time.Time
+	End   time.Time
+	// Slots are the numbers of the lesson slots of the window if it is aligned to the slots.
+	Slots []int
+}
+
+// Duration returns the length of the window.
+func (w Window) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// WindowOptions configures the search of free windows.
+type WindowOptions struct {
+	// MinDuration is the minimum length of a window.
+	MinDuration time.Duration
+	// AlignToSlots makes the windows consist of whole lesson slots of the bell schedule.
+	// Otherwise any free time within the working hours is a window.
+	AlignToSlots bool
+	// DayStart and DayEnd are the working hours of free-form windows as durations since midnight,
+	// 08:00 and 20:00 if zero.
+	DayStart time.Duration
+	DayEnd   time.Duration
+	// Concurrency is the maximum number of timetables fetched at once, 4 if zero.
+	Concurrency int
+}
+
+// workingHours returns the working hours of free-form windows.
+func (o WindowOptions) workingHours() (start, end time.Duration) {
+	start, end = o.DayStart, o.DayEnd
+	if start == 0 {
+		start = 8 * time.Hour
+	}
+	if end == 0 {
+		end = 20 * time.Hour
+	}
+	return start, end
+}
+
+// interval is a busy period of time.
+type interval struct {
+	start, end time.Time
+}
+
+// busyIntervals returns the periods the lessons and the reservations of the timetable take.
+func (tt timetable) busyIntervals() []interval {
+	var busy []interval
+	for _, lesson := range tt.Lessons {
+		busy = append(busy, interval{lesson.StartTime, lesson.EndTime})
+	}
+	for _, res := range tt.Reservations {
+		busy = append(busy, interval{res.StartTime, res.EndTime})
+	}
+	return busy
+}
+
+// FindTeacherWindows returns the windows between from and to when all the teachers are free.
+// The teacher timetables are fetched concurrently and go through the caches of the Api.
+// Aligned windows use the Bells of the Api, without them ErrNoBellSchedule is returned.
+// If some data is stale, the windows are returned with a *StaleError.
+func (a *Api) FindTeacherWindows(ctx context.Context, teachers []Teacher, from, to time.Time, opts WindowOptions) ([]Window, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid period: %v - %v", from, to)
+	}
+	var bells *BellSchedule
+	if opts.AlignToSlots {
+		var err error
+		bells, err = a.bellSchedule()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch the timetables of the teachers.
+	objs := make([]Object, len(teachers))
+	for i, teacher := range teachers {
+		objs[i] = teacher
+	}
+	tts, err := a.getTimetables(ctx, objs, from, to, opts.Concurrency)
+	if err != nil && !IsStale(err) {
+		return nil, err
+	}
+
+	// Every lesson of every teacher is busy time.
+	var busy []interval
+	for _, tt := range tts {
+		busy = append(busy, tt.busyIntervals()...)
+	}
+	return a.freeWindows(busy, bells, from, to, opts), err
+}
+
+// freeWindows returns the windows between from and to that don't overlap the busy intervals.
+// Aligned windows are made of the slots of bells.
+func (a *Api) freeWindows(busy []interval, bells *BellSchedule, from, to time.Time, opts WindowOptions) []Window {
+	// Find the candidate windows of every day.
+	var candidates []Window
+	loc := a.location()
+	if opts.AlignToSlots && bells.Location != nil {
+		loc = bells.Location
+	}
+	dayStart, dayEnd := opts.workingHours()
+	first := from.In(loc)
+	for day := atClock(first, 0); day.Before(to); day = atClock(day.AddDate(0, 0, 1), 0) {
+		if !opts.AlignToSlots {
+			candidates = append(candidates, Window{Start: atClock(day, dayStart), End: atClock(day, dayEnd)})
+			continue
+		}
+		for _, slot := range bells.Slots {
+			start, end := slot.On(day)
+			candidates = append(candidates, Window{Start: start, End: end, Slots: []int{slot.Number}})
+		}
+	}
+
+	// Cut the candidates to the period and remove the busy time.
+	sort.Slice(busy, func(i, j int) bool { return busy[i].start.Before(busy[j].start) })
+	var free []Window
+	for _, w := range candidates {
+		if w.Start.Before(from) {
+			if opts.AlignToSlots {
+				continue
+			}
+			w.Start = from
+		}
+		if w.End.After(to) {
+			if opts.AlignToSlots {
+				continue
+			}
+			w.End = to
+		}
+		free = append(free, subtractBusy(w, busy, opts.AlignToSlots)...)
+	}
+
+	// Join the consecutive slots, then drop the short windows.
+	if opts.AlignToSlots {
+		free = joinSlots(free, bells)
+	}
+	var windows []Window
+	for _, w := range free {
+		if w.Duration() > 0 && w.Duration() >= opts.MinDuration {
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// subtractBusy returns the parts of w that don't overlap the busy intervals, which are sorted by start.
+// An aligned window is either free as a whole or not at all.
+func subtractBusy(w Window, busy []interval, aligned bool) []Window {
+	var free []Window
+	for _, b := range busy {
+		if !overlaps(w.Start, w.End, b.start, b.end) {
+			continue
+		}
+		if aligned {
+			return nil
+		}
+		if b.start.After(w.Start) {
+			free = append(free, Window{Start: w.Start, End: b.start})
+		}
+		if b.end.After(w.Start) {
+			w.Start = b.end
+		}
+		if !w.Start.Before(w.End) {
+			return free
+		}
+	}
+	return append(free, w)
+}
+
+// joinSlots joins the free slots that follow each other in the bell schedule on the same day.
+func joinSlots(free []Window, bells *BellSchedule) []Window {
+	// next maps every slot number to the number of the slot after it.
+	next := make(map[int]int)
+	for i := 1; i < len(bells.Slots); i++ {
+		next[bells.Slots[i-1].Number] = bells.Slots[i].Number
+	}
+
+	var joined []Window
+	for _, w := range free {
+		if n := len(joined); n > 0 {
+			last := &joined[n-1]
+			lastSlot := last.Slots[len(last.Slots)-1]
+			if nextSlot, ok := next[lastSlot]; ok && nextSlot == w.Slots[0] && sameDay(last.End, w.Start) {
+				last.End = w.End
+				last.Slots = append(last.Slots, w.Slots...)
+				continue
+			}
+		}
+		joined = append(joined, w)
+	}
+	return joined
+}
+
+// sameDay reports whether a and b are on the same date.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
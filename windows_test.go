@@ -0,0 +1,121 @@
+package psrozklad
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindTeacherWindows(t *testing.T) {
+	lessonsOf := func(items string) string {
+		return `{"psrozklad_export": {"roz_items": [` + items + `], "code": "0"}}`
+	}
+	client := &lockedHttpClient{client: &routeHttpClient{routes: []route{
+		{"OBJ_ID=420&", lessonsOf(`
+			{"object": "Горобець Сергій Миколайович", "date": "16.10.2023", "lesson_number": "1", "lesson_time": "09:00-10:20"},
+			{"object": "Горобець Сергій Миколайович", "date": "16.10.2023", "lesson_number": "4", "lesson_time": "14:00-15:20"}`)},
+		{"OBJ_ID=486&", lessonsOf(`
+			{"object": "Яценко Олександр Сергійович", "date": "16.10.2023", "lesson_number": "2", "lesson_time": "10:30-11:50"},
+			{"object": "Яценко Олександр Сергійович", "date": "17.10.2023", "lesson_number": "3", "lesson_time": "12:20-13:40", "reservation": "Засідання кафедри"}`)},
+	}}}
+	bells, err := NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+		Slot{Number: 3, Start: clock(12, 20), End: clock(13, 40)},
+		Slot{Number: 4, Start: clock(14, 0), End: clock(15, 20)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api := newTestApi(t, WithHTTPClient(client), WithBellSchedule(bells))
+	teachers := []Teacher{{ShortName: "Горобець С.М.", Id: 420}, {ShortName: "Яценко О.С.", Id: 486}}
+	at := func(d, h, m int) time.Time {
+		return time.Date(2023, time.October, d, h, m, 0, 0, Kyiv)
+	}
+
+	testCases := []struct {
+		desc     string
+		from, to time.Time
+		opts     WindowOptions
+		want     []Window
+	}{
+		{
+			desc: "aligned",
+			from: at(16, 0, 0),
+			to:   at(18, 0, 0),
+			opts: WindowOptions{AlignToSlots: true},
+			want: []Window{
+				{Start: at(16, 12, 20), End: at(16, 13, 40), Slots: []int{3}},
+				{Start: at(17, 9, 0), End: at(17, 11, 50), Slots: []int{1, 2}},
+				{Start: at(17, 14, 0), End: at(17, 15, 20), Slots: []int{4}},
+			},
+		},
+		{
+			desc: "aligned with a minimum duration",
+			from: at(16, 0, 0),
+			to:   at(18, 0, 0),
+			opts: WindowOptions{AlignToSlots: true, MinDuration: 2 * time.Hour},
+			want: []Window{
+				{Start: at(17, 9, 0), End: at(17, 11, 50), Slots: []int{1, 2}},
+			},
+		},
+		{
+			desc: "free-form",
+			from: at(16, 9, 30),
+			to:   at(16, 18, 0),
+			opts: WindowOptions{MinDuration: 10 * time.Minute, DayEnd: clock(17, 0)},
+			want: []Window{
+				{Start: at(16, 10, 20), End: at(16, 10, 30)},
+				{Start: at(16, 11, 50), End: at(16, 14, 0)},
+				{Start: at(16, 15, 20), End: at(16, 17, 0)},
+			},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			got, err := api.FindTeacherWindows(context.Background(), teachers, tC.from, tC.to, tC.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tC.want) {
+				t.Errorf("want: \n%v\ngot: \n%v", tC.want, got)
+			}
+		})
+	}
+}
+
+func TestFindTeacherWindowsNoLessons(t *testing.T) {
+	client := &routeHttpClient{routes: []route{
+		{"OBJ_ID=7&", `{"psrozklad_export": {"roz_items": [], "code": "0"}}`},
+	}}
+	teachers := []Teacher{{ShortName: "Новенький Н.Н.", Id: 7}}
+	from := time.Date(2023, time.October, 16, 0, 0, 0, 0, Kyiv)
+	to := from.AddDate(0, 0, 1)
+
+	// Aligned windows need a bell schedule, it can't be learned from no lessons.
+	api := newTestApi(t, WithHTTPClient(client))
+	_, err := api.FindTeacherWindows(context.Background(), teachers, from, to, WindowOptions{AlignToSlots: true})
+	if !errors.Is(err, ErrNoBellSchedule) {
+		t.Errorf("want ErrNoBellSchedule, got: %v", err)
+	}
+
+	// A teacher without lessons is free in every slot.
+	bells, err := NewBellSchedule(Kyiv,
+		Slot{Number: 1, Start: clock(9, 0), End: clock(10, 20)},
+		Slot{Number: 2, Start: clock(10, 30), End: clock(11, 50)},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.Bells = bells
+	got, err := api.FindTeacherWindows(context.Background(), teachers, from, to, WindowOptions{AlignToSlots: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Window{{Start: from.Add(9 * time.Hour), End: from.Add(11*time.Hour + 50*time.Minute), Slots: []int{1, 2}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want: \n%v\ngot: \n%v", want, got)
+	}
+}